@@ -0,0 +1,143 @@
+package errx
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// BizErrorDef 是预定义的 BizError ，由错误码和错误描述的模板组成，通常声明为包级别的变量：
+//
+//	var ErrUserNotFound = &errx.BizErrorDef{Code: 1001, Template: "user {id} not found"}
+//
+// 再通过 New() 或 Wrap() 创建 BizError ：
+//
+//	return ErrUserNotFound.Wrap(err, errx.Field{Key: "id", Value: id})
+//
+// 模板中的占位符有两种形式：
+//   - {N} ：N 是从 0 开始的整数，表示第 N 个参数；若参数是 Field ，取其 Value ；
+//   - {name} ：表示 Key 为 name 的 Field 参数的 Value 。
+//
+// 参数值通过 fmt.Sprint() 转换为字符串。没有对应参数的占位符原样保留。
+// 使用“{{”和“}}”表示字面的“{”和“}”。
+//
+// BizErrorDef 实现 error ，可作为 errors.Is() 的 target ，匹配错误链上错误码相同的 BizError 。
+type BizErrorDef struct {
+	// Code 是错误码。
+	Code int
+
+	// Template 是错误描述的模板。
+	Template string
+}
+
+var _ error = (*BizErrorDef)(nil)
+
+// Error 实现 error 接口，以便作为 errors.Is() 的 target ，格式为： (Code) Template 。
+func (d *BizErrorDef) Error() string {
+	return "(" + strconv.Itoa(d.Code) + ") " + d.Template
+}
+
+// TemplateCarrier 是通过 BizErrorDef 创建的错误，可以获取生成错误描述所用的模板和参数。
+type TemplateCarrier interface {
+	// Template 返回错误描述的模板。若错误不是通过模板创建的，返回空字符串。
+	Template() string
+
+	// TemplateArgs 返回创建错误时给定的模板参数。
+	TemplateArgs() []interface{}
+}
+
+var _ TemplateCarrier = (*bizErr)(nil)
+
+// New 创建一个 BizError ，其错误码为 Code ，错误描述由 Template 和给定的参数生成，调用栈从调用 New() 的位置开始。
+// 参数中的 Field 同时作为键值对附加在错误上，见 FieldCarrier 。
+func (d *BizErrorDef) New(args ...interface{}) BizError {
+	return d.newError(nil, args, captureStack(CaptureInfo{Kind: CaptureBizError, Code: d.Code}, 3)) // 调用栈不包括当前函数。
+}
+
+// Wrap 与 New() 相同，但指定引起此错误的错误，可以为 nil 。
+func (d *BizErrorDef) Wrap(cause error, args ...interface{}) BizError {
+	return d.newError(cause, args, captureStack(CaptureInfo{Kind: CaptureBizError, Cause: cause, Code: d.Code}, 3)) // 调用栈不包括当前函数。
+}
+
+func (d *BizErrorDef) newError(cause error, args []interface{}, stack ErrorStack) BizError {
+	var fields []Field
+	for _, arg := range args {
+		if f, ok := arg.(Field); ok {
+			fields = append(fields, f)
+		}
+	}
+
+	return &bizErr{
+		ErrorCause:  ErrorCause{cause},
+		ErrorStack:  stack,
+		ErrorFields: ErrorFields{fields},
+		code:        d.Code,
+		message:     renderTemplate(d.Template, args),
+		template:    d.Template,
+		args:        args,
+	}
+}
+
+// renderTemplate 将模板中的占位符替换为参数值，格式见 BizErrorDef 。
+func renderTemplate(template string, args []interface{}) string {
+	var b strings.Builder
+	for i := 0; i < len(template); i++ {
+		c := template[i]
+
+		if c == '}' {
+			b.WriteByte(c)
+			if i+1 < len(template) && template[i+1] == '}' {
+				i++
+			}
+			continue
+		}
+
+		if c != '{' {
+			b.WriteByte(c)
+			continue
+		}
+
+		if i+1 < len(template) && template[i+1] == '{' {
+			b.WriteByte('{')
+			i++
+			continue
+		}
+
+		end := strings.IndexByte(template[i+1:], '}')
+		if end < 0 {
+			b.WriteString(template[i:])
+			break
+		}
+
+		name := template[i+1 : i+1+end]
+		if v, ok := templateArg(name, args); ok {
+			b.WriteString(fmt.Sprint(v))
+		} else {
+			b.WriteString(template[i : i+end+2])
+		}
+		i += end + 1
+	}
+	return b.String()
+}
+
+// templateArg 返回占位符对应的参数值。
+func templateArg(name string, args []interface{}) (interface{}, bool) {
+	if idx, err := strconv.Atoi(name); err == nil {
+		if idx < 0 || idx >= len(args) {
+			return nil, false
+		}
+
+		if f, ok := args[idx].(Field); ok {
+			return f.Value, true
+		}
+		return args[idx], true
+	}
+
+	// 同名的参数，后面的优先，与 Fields() 的规则一致。
+	for i := len(args) - 1; i >= 0; i-- {
+		if f, ok := args[i].(Field); ok && f.Key == name {
+			return f.Value, true
+		}
+	}
+	return nil, false
+}
@@ -0,0 +1,34 @@
+package errx_test
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/cmstar/go-errx"
+)
+
+// ErrUserNotFound 是预定义的 BizError 。
+var ErrUserNotFound = &errx.BizErrorDef{Code: 1001, Template: "user {id} not found"}
+
+func ExampleBizErrorDef() {
+	// 当前示例演示如何通过 BizErrorDef 创建 BizError 。
+
+	err := ErrUserNotFound.Wrap(errors.New("no rows"), errx.Field{Key: "id", Value: "u1"})
+	fmt.Println(err)
+	fmt.Println(err.Cause())
+	fmt.Println(errx.Fields(err))
+
+	tc := err.(errx.TemplateCarrier)
+	fmt.Println(tc.Template())
+
+	// errors.Is 按错误码匹配错误链上的 BizError 。
+	wrapped := fmt.Errorf("load profile: %w", err)
+	fmt.Println(errors.Is(wrapped, ErrUserNotFound))
+
+	// Output:
+	// (1001) user u1 not found
+	// no rows
+	// map[id:u1]
+	// user {id} not found
+	// true
+}
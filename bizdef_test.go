@@ -0,0 +1,63 @@
+package errx
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestBizErrorDef(t *testing.T) {
+	def := &BizErrorDef{Code: 1001, Template: "user {id} not found in {0}"}
+
+	t.Run("new", func(t *testing.T) {
+		err := def.New(Field{"id", 12}, "db")
+		require.Equal(t, 1001, err.Code())
+		require.Equal(t, "user 12 not found in 12", err.Message())
+		require.Nil(t, err.Cause())
+		require.Equal(t, "github.com/cmstar/go-errx.TestBizErrorDef.func1", err.(StackTracer).Frames()[0].Function)
+
+		tc := err.(TemplateCarrier)
+		require.Equal(t, def.Template, tc.Template())
+		require.Equal(t, []interface{}{Field{"id", 12}, "db"}, tc.TemplateArgs())
+
+		require.Equal(t, []Field{{"id", 12}}, err.(FieldCarrier).Fields())
+	})
+
+	t.Run("wrap", func(t *testing.T) {
+		cause := errors.New("gg")
+		err := def.Wrap(cause, "db", Field{"id", "u1"})
+		require.Equal(t, "(1001) user u1 not found in db", err.Error())
+		require.Equal(t, cause, err.Cause())
+		require.Equal(t, "github.com/cmstar/go-errx.TestBizErrorDef.func2", err.(StackTracer).Frames()[0].Function)
+	})
+
+	t.Run("not-template", func(t *testing.T) {
+		tc := NewBizError(1, "m", nil).(TemplateCarrier)
+		require.Equal(t, "", tc.Template())
+		require.Nil(t, tc.TemplateArgs())
+	})
+}
+
+func TestRenderTemplate(t *testing.T) {
+	cases := []struct {
+		template string
+		args     []interface{}
+		want     string
+	}{
+		{"", nil, ""},
+		{"plain", []interface{}{1}, "plain"},
+		{"{0}-{1}-{0}", []interface{}{"a", 2}, "a-2-a"},
+		{"{name}", []interface{}{Field{"name", "a"}, Field{"name", "b"}}, "b"},
+		{"{2} {x} {-1}", []interface{}{1}, "{2} {x} {-1}"},
+		{"{{0}} {0}}", []interface{}{1}, "{0} 1}"},
+		{"{unclosed", []interface{}{1}, "{unclosed"},
+		{"{}", []interface{}{1}, "{}"},
+	}
+
+	for _, c := range cases {
+		t.Run(c.template, func(t *testing.T) {
+			require.Equal(t, c.want, renderTemplate(c.template, c.args))
+		})
+	}
+}
@@ -1,6 +1,8 @@
 package errx
 
 import (
+	"encoding/json"
+	"fmt"
 	"strconv"
 	"strings"
 )
@@ -27,12 +29,20 @@ type BizError interface {
 type bizErr struct {
 	ErrorCause
 	ErrorStack
-	code    int
-	message string
+	ErrorFields
+	code     int
+	message  string
+	template string        // 通过 BizErrorDef 创建时的模板。
+	args     []interface{} // 通过 BizErrorDef 创建时的模板参数。
 }
 
 // Ensure implementation.
 var _ BizError = (*bizErr)(nil)
+var _ StackTracer = (*bizErr)(nil)
+var _ FieldCarrier = (*bizErr)(nil)
+var _ BizFieldAttacher = (*bizErr)(nil)
+var _ json.Marshaler = (*bizErr)(nil)
+var _ fmt.Formatter = (*bizErr)(nil)
 
 // Code 返回错误码。通常 0 表示没有错误。
 func (e *bizErr) Code() int {
@@ -44,6 +54,29 @@ func (e *bizErr) Message() string {
 	return e.message
 }
 
+// Template 实现 TemplateCarrier.Template() 。
+func (e *bizErr) Template() string {
+	return e.template
+}
+
+// TemplateArgs 实现 TemplateCarrier.TemplateArgs() 。返回的是一个副本。
+func (e *bizErr) TemplateArgs() []interface{} {
+	if len(e.args) == 0 {
+		return nil
+	}
+
+	res := make([]interface{}, len(e.args))
+	copy(res, e.args)
+	return res
+}
+
+// With 实现 BizFieldAttacher.With() 。
+func (e *bizErr) With(key string, value interface{}) BizError {
+	res := *e
+	res.ErrorFields = e.ErrorFields.with(Field{key, value})
+	return &res
+}
+
 // ErrorWithoutStack 实现 StackfulError.ErrorWithoutStack() 。
 func (e *bizErr) ErrorWithoutStack() string {
 	// BizError.Error() 本来就没调用栈，直接用。
@@ -61,15 +94,33 @@ func (e *bizErr) Error() string {
 	return res
 }
 
+// Format 实现 fmt.Formatter ，规则与 ErrorWrapper.Format() 相同。
+// 其中 %v 输出 Error() ，不含调用栈；要输出完整的错误链，使用 %+v 。
+// 另外以 %d 、 %x 等整数格式输出错误码，如 %04d 。
+func (e *bizErr) Format(f fmt.State, verb rune) {
+	formatStackful(f, verb, e)
+}
+
+// Is 用于支持 errors.Is() ：若 target 是错误码相同的 BizError 或 *BizErrorDef ，返回 true 。
+// 于是 errors.Is(err, ErrUserNotFound) 可判断错误链上是否有对应错误码的 BizError 。
+func (e *bizErr) Is(target error) bool {
+	return isBizCode(e.code, target)
+}
+
+// MarshalJSON 实现 json.Marshaler ，输出整个错误链，格式见 ErrorChain 。
+func (e *bizErr) MarshalJSON() ([]byte, error) {
+	return MarshalChain(e)
+}
+
 // NewBizError 创建一个 BizError ，给定错误码、错误信息和引起此错误的错误。
 // cause 指定引发此错误的错误，可以为 nil 。
-// 此方法创建的 BizError 会包含方法调用栈信息。
+// 此方法创建的 BizError 会包含方法调用栈信息，但也可通过调用栈记录策略省去，见 SetCapturePolicy() 。
 func NewBizError(code int, message string, cause error) BizError {
 	bizErr := &bizErr{
 		code:       code,
 		message:    message,
 		ErrorCause: ErrorCause{cause},
-		ErrorStack: GetErrorStack(3), // 调用栈不包括当前函数。
+		ErrorStack: captureStack(CaptureInfo{Kind: CaptureBizError, Cause: cause, Code: code}, 3), // 调用栈不包括当前函数。
 	}
 	return bizErr
 }
@@ -85,3 +136,45 @@ func NewBizErrorWithoutStack(code int, message string, cause error) BizError {
 	}
 	return bizErr
 }
+
+// CodeOf 返回错误链上第一个 BizError 的错误码，见 FindBizError() 。
+// 若错误链上没有 BizError ，第二个返回值为 false 。
+func CodeOf(err error) (int, bool) {
+	biz := FindBizError(err)
+	if biz == nil {
+		return 0, false
+	}
+	return biz.Code(), true
+}
+
+// HasCode 判断错误链上是否有错误码为给定值之一的 BizError 。与 CodeOf() 不同，它会检查错误链上所有的 BizError ，
+// 包括 Unwrap() []error 的各个分支中的，遍历的规则见 Walk() 。
+func HasCode(err error, codes ...int) bool {
+	found := false
+	Walk(err, func(_ int, e error) bool {
+		biz, ok := e.(BizError)
+		if !ok {
+			return true
+		}
+
+		for _, code := range codes {
+			if biz.Code() == code {
+				found = true
+				return false
+			}
+		}
+		return true
+	})
+	return found
+}
+
+// isBizCode 判断 target 是否是错误码为 code 的 BizError 或 *BizErrorDef 。
+func isBizCode(code int, target error) bool {
+	switch t := target.(type) {
+	case BizError:
+		return t.Code() == code
+	case *BizErrorDef:
+		return t != nil && t.Code == code
+	}
+	return false
+}
@@ -2,6 +2,7 @@ package errx
 
 import (
 	"errors"
+	"fmt"
 	"testing"
 
 	"github.com/stretchr/testify/require"
@@ -30,3 +31,40 @@ func TestNewBizErrorWithoutStack(t *testing.T) {
 	a.Equal("(123) msg", got.Error())
 	a.Equal("", got.Stack())
 }
+
+func TestBizError_Is(t *testing.T) {
+	def := &BizErrorDef{Code: 1, Template: "t"}
+	err := Wrap("p1", fmt.Errorf("p2: %w", NewBizError(1, "m", nil)))
+
+	require.True(t, errors.Is(err, def))
+	require.True(t, errors.Is(err, NewBizErrorWithoutStack(1, "other", nil)))
+	require.False(t, errors.Is(err, &BizErrorDef{Code: 2}))
+	require.False(t, errors.Is(err, NewBizError(2, "m", nil)))
+	require.False(t, errors.Is(err, errors.New("(1) m")))
+
+	remote := NewErrorChain(err).Err()
+	require.True(t, errors.Is(remote, def))
+	require.False(t, errors.Is(remote, &BizErrorDef{Code: 2}))
+}
+
+func TestCodeOf(t *testing.T) {
+	code, ok := CodeOf(nil)
+	require.False(t, ok)
+	require.Equal(t, 0, code)
+
+	_, ok = CodeOf(Wrap("p", errors.New("gg")))
+	require.False(t, ok)
+
+	code, ok = CodeOf(Wrap("p", NewBizError(2, "outer", NewBizError(1, "inner", nil))))
+	require.True(t, ok)
+	require.Equal(t, 2, code)
+}
+
+func TestHasCode(t *testing.T) {
+	err := Wrap("p", NewBizError(2, "outer", NewBizError(1, "inner", nil)))
+	require.True(t, HasCode(err, 1))
+	require.True(t, HasCode(err, 3, 2))
+	require.False(t, HasCode(err, 3))
+	require.False(t, HasCode(err))
+	require.False(t, HasCode(nil, 1))
+}
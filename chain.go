@@ -0,0 +1,131 @@
+package errx
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// ErrorChain 是错误链的结构化表示，可被序列化为 JSON 。第一个元素是最外层的错误，其后逐层是内部错误。
+// 可通过 NewErrorChain() 创建。
+type ErrorChain []ChainLayer
+
+// ChainLayer 表示错误链中的一层错误。
+type ChainLayer struct {
+	// Message 是错误的描述，不含调用栈。对于 StackfulError 是 ErrorWithoutStack() ，其他错误是 Error() 。
+	Message string `json:"message"`
+
+	// Type 是错误的 Go 类型，如 *errx.ErrorWrapper 。
+	Type string `json:"type"`
+
+	// Code 是 BizError 的错误码。若当前错误不是 BizError ，为 nil 。
+	Code *int `json:"code,omitempty"`
+
+	// BizMessage 是 BizError.Message() 。若当前错误不是 BizError ，为空字符串。
+	BizMessage string `json:"bizMessage,omitempty"`
+
+	// Stack 是错误的调用栈，仅在错误实现 StackTracer 或有 pkg/errors 风格的 StackTrace() 方法时记录。
+	Stack []Frame `json:"stack,omitempty"`
+
+	// RawStack 是 StackfulError.Stack() 的文本，仅在错误是 StackfulError 但不能通过 StackTracer 得到 Frame 时记录。
+	RawStack string `json:"rawStack,omitempty"`
+
+	// Fields 是当前这一层错误上的键值对，仅在错误实现 FieldCarrier 时记录。若有相同的键，后添加的值优先。
+	Fields map[string]interface{} `json:"fields,omitempty"`
+
+	// Branches 仅在错误有多个内部错误（实现 Unwrap() []error ，如 Multi 和 errors.Join() 的结果）时记录，
+	// 每个内部错误各自转换为一个 ErrorChain ，保留其调用栈、错误码等。此时当前层是错误链的最后一层。
+	Branches []ErrorChain `json:"branches,omitempty"`
+}
+
+// NewErrorChain 将给定的错误转换为 ErrorChain 。若给定 nil ，返回 nil 。
+//
+// 与 Describe() 一样，它通过 errors.Unwrap() 逐层获取内部错误，错误链中有环时，到重复的错误之前停止，见 Chain() 。
+// 遇到有多个内部错误的错误时，各个内部错误记录在 ChainLayer.Branches 中。
+func NewErrorChain(err error) ErrorChain {
+	return newErrorChain(err, make(errorSet))
+}
+
+// newErrorChain 实现 NewErrorChain() ， visited 是当前路径上外层的错误，用于检测环。
+func newErrorChain(err error, visited errorSet) ErrorChain {
+	chain := Chain(err)
+	for i, e := range chain {
+		if visited.has(e) {
+			// 分支中的错误引用了外层的错误，形成环，与 Chain() 一样到重复的错误之前停止。
+			chain = chain[:i]
+			break
+		}
+	}
+	if len(chain) == 0 {
+		return nil
+	}
+
+	res := make(ErrorChain, len(chain))
+	for i, e := range chain {
+		res[i] = newChainLayer(e)
+	}
+
+	last := chain[len(chain)-1]
+	if multi, ok := last.(interface{ Unwrap() []error }); ok {
+		// 仅记录当前路径上的错误，以免同一个错误出现在多个分支时被误判为环。
+		for _, e := range chain {
+			visited.add(e)
+		}
+		defer func() {
+			for _, e := range chain {
+				visited.remove(e)
+			}
+		}()
+
+		layer := &res[len(res)-1]
+		for _, e := range multi.Unwrap() {
+			if branch := newErrorChain(e, visited); len(branch) > 0 {
+				layer.Branches = append(layer.Branches, branch)
+			}
+		}
+	}
+	return res
+}
+
+// MarshalChain 将给定的错误转换为 ErrorChain 并序列化为 JSON 。若给定 nil ，返回 null 。
+func MarshalChain(err error) ([]byte, error) {
+	return json.Marshal(NewErrorChain(err))
+}
+
+func newChainLayer(err error) ChainLayer {
+	layer := ChainLayer{
+		Type: fmt.Sprintf("%T", err),
+	}
+
+	switch e := err.(type) {
+	case StackfulError:
+		layer.Message = e.ErrorWithoutStack()
+
+		if st, ok := e.(StackTracer); ok {
+			layer.Stack = st.Frames()
+		}
+		if len(layer.Stack) == 0 {
+			layer.RawStack = e.Stack()
+		}
+
+	default:
+		layer.Message = e.Error()
+		layer.Stack = foreignFrames(e)
+	}
+
+	if biz, ok := err.(BizError); ok {
+		code := biz.Code()
+		layer.Code = &code
+		layer.BizMessage = biz.Message()
+	}
+
+	if fc, ok := err.(FieldCarrier); ok {
+		if fields := fc.Fields(); len(fields) > 0 {
+			layer.Fields = make(map[string]interface{}, len(fields))
+			for _, f := range fields {
+				layer.Fields[f.Key] = f.Value
+			}
+		}
+	}
+
+	return layer
+}
@@ -0,0 +1,146 @@
+package errx
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewErrorChain(t *testing.T) {
+	t.Run("nil", func(t *testing.T) {
+		require.Nil(t, NewErrorChain(nil))
+	})
+
+	t.Run("chain", func(t *testing.T) {
+		err := Wrap("p1", fmt.Errorf("p2: %w", NewBizError(12, "biz", errors.New("gg"))))
+		chain := NewErrorChain(err)
+		require.Len(t, chain, 4)
+
+		c := chain[0]
+		require.Equal(t, "p1: p2: (12) biz", c.Message)
+		require.Equal(t, "*errx.ErrorWrapper", c.Type)
+		require.Nil(t, c.Code)
+		require.Equal(t, "", c.BizMessage)
+		require.NotEmpty(t, c.Stack)
+		require.Equal(t, "github.com/cmstar/go-errx.TestNewErrorChain.func2", c.Stack[0].Function)
+		require.Equal(t, "", c.RawStack)
+
+		c = chain[1]
+		require.Equal(t, "p2: (12) biz", c.Message)
+		require.Equal(t, "*fmt.wrapError", c.Type)
+		require.Nil(t, c.Stack)
+
+		c = chain[2]
+		require.Equal(t, "(12) biz", c.Message)
+		require.Equal(t, "*errx.bizErr", c.Type)
+		require.Equal(t, 12, *c.Code)
+		require.Equal(t, "biz", c.BizMessage)
+		require.NotEmpty(t, c.Stack)
+
+		c = chain[3]
+		require.Equal(t, ChainLayer{Message: "gg", Type: "*errors.errorString"}, c)
+	})
+
+	t.Run("branches", func(t *testing.T) {
+		shared := errors.New("shared")
+		m := NewMulti("m").
+			Append(NewBizError(2, "b", shared)).
+			Append(Wrap("w", shared))
+		chain := NewErrorChain(Wrap("outer", m))
+		require.Len(t, chain, 2)
+		require.Nil(t, chain[0].Branches)
+
+		c := chain[1]
+		require.Equal(t, "*errx.Multi", c.Type)
+		require.NotEmpty(t, c.Stack)
+		require.Len(t, c.Branches, 2)
+
+		// 每个分支保留其错误码和调用栈，同一个错误出现在多个分支时不被误判为环。
+		b := c.Branches[0]
+		require.Len(t, b, 2)
+		require.Equal(t, 2, *b[0].Code)
+		require.NotEmpty(t, b[0].Stack)
+		require.Equal(t, ChainLayer{Message: "shared", Type: "*errors.errorString"}, b[1])
+
+		b = c.Branches[1]
+		require.Len(t, b, 2)
+		require.Equal(t, "w: shared", b[0].Message)
+		require.NotEmpty(t, b[0].Stack)
+	})
+
+	t.Run("branches-cycle", func(t *testing.T) {
+		m := NewMulti("m")
+		m.Append(errors.New("a"), Wrap("w", m))
+		chain := NewErrorChain(m)
+		require.Len(t, chain, 1)
+		require.Len(t, chain[0].Branches, 2)
+		require.Equal(t, ErrorChain{{Message: "a", Type: "*errors.errorString"}}, chain[0].Branches[0])
+
+		// 引用外层的错误之前停止。
+		b := chain[0].Branches[1]
+		require.Len(t, b, 1)
+		require.Equal(t, "*errx.ErrorWrapper", b[0].Type)
+	})
+
+	t.Run("raw-stack", func(t *testing.T) {
+		chain := NewErrorChain(rawStackError{})
+		require.Equal(t, ChainLayer{Message: "raw", Type: "errx.rawStackError", RawStack: "stack"}, chain[0])
+	})
+}
+
+func TestMarshalChain(t *testing.T) {
+	t.Run("nil", func(t *testing.T) {
+		data, err := MarshalChain(nil)
+		require.NoError(t, err)
+		require.Equal(t, "null", string(data))
+	})
+
+	t.Run("no-stack", func(t *testing.T) {
+		data, err := MarshalChain(WrapWithoutStack("p1", NewBizErrorWithoutStack(0, "biz", errors.New("gg"))))
+		require.NoError(t, err)
+		require.JSONEq(t, `[
+			{"message":"p1: (0) biz","type":"*errx.ErrorWrapper"},
+			{"message":"(0) biz","type":"*errx.bizErr","code":0,"bizMessage":"biz"},
+			{"message":"gg","type":"*errors.errorString"}
+		]`, string(data))
+	})
+
+	t.Run("stack", func(t *testing.T) {
+		data, err := MarshalChain(Wrap("p1", nil))
+		require.NoError(t, err)
+		require.Regexp(t, `^\[\{"message":"p1","type":"\*errx\.ErrorWrapper","stack":\[\{"function":"github\.com/cmstar/go-errx\.TestMarshalChain\.func3","package":"github\.com/cmstar/go-errx","file":"[^"]+chain_test\.go","line":\d+\},`, string(data))
+	})
+}
+
+func TestMarshalJSON(t *testing.T) {
+	type holder struct {
+		Err error `json:"err"`
+	}
+
+	check := func(err error) {
+		want, e := MarshalChain(err)
+		require.NoError(t, e)
+
+		got, e := json.Marshal(err)
+		require.NoError(t, e)
+		require.Equal(t, string(want), string(got))
+
+		got, e = json.Marshal(holder{err})
+		require.NoError(t, e)
+		require.Equal(t, `{"err":`+string(want)+`}`, string(got))
+	}
+
+	check(Wrap("p1", errors.New("gg")))
+	check(NewBizError(1, "biz", Wrap("p1", nil)))
+	check(NewMulti("m").Append(errors.New("a")))
+}
+
+type rawStackError struct{}
+
+func (rawStackError) Error() string             { return "raw\n--- stack" }
+func (rawStackError) ErrorWithoutStack() string { return "raw" }
+func (rawStackError) Cause() error              { return nil }
+func (rawStackError) Stack() string             { return "stack" }
@@ -0,0 +1,195 @@
+// Package compat 提供与 github.com/pkg/errors 相同的函数，以便将使用 pkg/errors 的代码迁移到 errx ：
+// 将导入路径 github.com/pkg/errors 替换为 github.com/cmstar/go-errx/compat 即可。
+//
+// 各函数的语义与 pkg/errors 相同，得到的错误支持 Cause() 、 errors.Unwrap() 和 StackTrace() ，
+// 可以被 errx.Describe() 等识别并输出调用栈。 %+v 的输出格式也与 pkg/errors 相同。
+//
+// 与 pkg/errors 一样，带有调用栈的函数总是记录调用栈，不受 errx.SetCapturePolicy() 影响。
+package compat
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+
+	"github.com/cmstar/go-errx"
+)
+
+// Frame 是调用栈中的一层调用，同 pkg/errors 的 Frame 。
+type Frame = errx.StackFrame
+
+// StackTrace 是调用栈，同 pkg/errors 的 StackTrace 。
+type StackTrace = errx.StackTrace
+
+// New 返回给定描述的错误，并记录调用栈。
+func New(message string) error {
+	return &stackError{
+		ErrorStack: errx.GetErrorStack(3), // 调用栈不包括当前函数。
+		msg:        message,
+		hasMsg:     true,
+	}
+}
+
+// Errorf 以 fmt.Sprintf() 格式化错误描述，并记录调用栈。与 pkg/errors 一样，不支持 %w 。
+func Errorf(format string, args ...interface{}) error {
+	return &stackError{
+		ErrorStack: errx.GetErrorStack(3), // 调用栈不包括当前函数。
+		msg:        fmt.Sprintf(format, args...),
+		hasMsg:     true,
+	}
+}
+
+// WithStack 为给定的错误记录调用栈，错误描述不变。若 err 为 nil ，返回 nil 。
+func WithStack(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	return &stackError{
+		ErrorCause: errx.ErrorCause{Err: err},
+		ErrorStack: errx.GetErrorStack(3), // 调用栈不包括当前函数。
+	}
+}
+
+// Wrap 封装给定的错误并记录调用栈，错误描述的格式为： message: err.Error() 。若 err 为 nil ，返回 nil 。
+func Wrap(err error, message string) error {
+	if err == nil {
+		return nil
+	}
+
+	return &stackError{
+		ErrorCause: errx.ErrorCause{Err: err},
+		ErrorStack: errx.GetErrorStack(3), // 调用栈不包括当前函数。
+		msg:        message,
+		hasMsg:     true,
+	}
+}
+
+// Wrapf 与 Wrap() 相同，但以 fmt.Sprintf() 格式化 message 。若 err 为 nil ，返回 nil 。
+func Wrapf(err error, format string, args ...interface{}) error {
+	if err == nil {
+		return nil
+	}
+
+	return &stackError{
+		ErrorCause: errx.ErrorCause{Err: err},
+		ErrorStack: errx.GetErrorStack(3), // 调用栈不包括当前函数。
+		msg:        fmt.Sprintf(format, args...),
+		hasMsg:     true,
+	}
+}
+
+// WithMessage 封装给定的错误，错误描述的格式为： message: err.Error() ，不记录调用栈。若 err 为 nil ，返回 nil 。
+func WithMessage(err error, message string) error {
+	if err == nil {
+		return nil
+	}
+
+	return &stackError{
+		ErrorCause: errx.ErrorCause{Err: err},
+		msg:        message,
+		hasMsg:     true,
+	}
+}
+
+// WithMessagef 与 WithMessage() 相同，但以 fmt.Sprintf() 格式化 message 。若 err 为 nil ，返回 nil 。
+func WithMessagef(err error, format string, args ...interface{}) error {
+	if err == nil {
+		return nil
+	}
+
+	return &stackError{
+		ErrorCause: errx.ErrorCause{Err: err},
+		msg:        fmt.Sprintf(format, args...),
+		hasMsg:     true,
+	}
+}
+
+// Cause 沿着 Cause() 方法逐层查找，返回最内层的错误，即第一个没有 Cause() 方法或 Cause() 返回 nil 的错误。
+// 若 err 为 nil ，返回 nil 。
+func Cause(err error) error {
+	for err != nil {
+		c, ok := err.(interface{ Cause() error })
+		if !ok {
+			break
+		}
+
+		cause := c.Cause()
+		if cause == nil {
+			break
+		}
+		err = cause
+	}
+	return err
+}
+
+// Is 同 errors.Is() 。
+func Is(err, target error) bool {
+	return errors.Is(err, target)
+}
+
+// As 同 errors.As() 。
+func As(err error, target interface{}) bool {
+	return errors.As(err, target)
+}
+
+// Unwrap 同 errors.Unwrap() 。
+func Unwrap(err error) error {
+	return errors.Unwrap(err)
+}
+
+// stackError 是此包的函数返回的错误。
+type stackError struct {
+	errx.ErrorCause
+	errx.ErrorStack
+	msg    string
+	hasMsg bool // 为 false 时，错误描述即是 Cause 的描述，见 WithStack() 。
+}
+
+var _ errx.StackTracer = (*stackError)(nil)
+var _ fmt.Formatter = (*stackError)(nil)
+
+// Error 实现 error 接口。
+func (e *stackError) Error() string {
+	switch {
+	case e.Err == nil:
+		return e.msg
+	case !e.hasMsg:
+		return e.Err.Error()
+	default:
+		return e.msg + ": " + e.Err.Error()
+	}
+}
+
+// Format 实现 fmt.Formatter 。 %s 和 %v 输出 Error() ， %q 输出其转义的形式。
+// %+v 与 pkg/errors 相同：依次输出 Cause 的 %+v 、错误描述和调用栈，调用栈每行一个 Frame ，格式见 errx.StackFrame 。
+func (e *stackError) Format(s fmt.State, verb rune) {
+	switch verb {
+	case 'v':
+		if s.Flag('+') {
+			e.formatVerbose(s)
+			return
+		}
+		io.WriteString(s, e.Error())
+	case 's':
+		io.WriteString(s, e.Error())
+	case 'q':
+		io.WriteString(s, strconv.Quote(e.Error()))
+	}
+}
+
+// formatVerbose 输出 %+v 的格式。
+func (e *stackError) formatVerbose(s fmt.State) {
+	switch {
+	case e.Err == nil:
+		io.WriteString(s, e.msg)
+	case !e.hasMsg:
+		fmt.Fprintf(s, "%+v", e.Err)
+	default:
+		fmt.Fprintf(s, "%+v\n", e.Err)
+		io.WriteString(s, e.msg)
+	}
+
+	fmt.Fprintf(s, "%+v", e.StackTrace())
+}
@@ -0,0 +1,105 @@
+package compat
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/cmstar/go-errx"
+	"github.com/stretchr/testify/require"
+)
+
+type stackTracer interface {
+	StackTrace() StackTrace
+}
+
+func TestNew(t *testing.T) {
+	err := New("gg")
+	require.Equal(t, "gg", err.Error())
+	require.Nil(t, errors.Unwrap(err))
+	require.Equal(t, "TestNew", fmt.Sprintf("%n", err.(stackTracer).StackTrace()[0]))
+
+	err = Errorf("a %d", 1)
+	require.Equal(t, "a 1", err.Error())
+	require.Equal(t, "TestNew", fmt.Sprintf("%n", err.(stackTracer).StackTrace()[0]))
+}
+
+func TestWrap(t *testing.T) {
+	cause := errors.New("gg")
+
+	require.Nil(t, Wrap(nil, "m"))
+	require.Nil(t, Wrapf(nil, "m"))
+	require.Nil(t, WithStack(nil))
+	require.Nil(t, WithMessage(nil, "m"))
+	require.Nil(t, WithMessagef(nil, "m"))
+
+	cases := []struct {
+		err      error
+		msg      string
+		hasStack bool
+	}{
+		{Wrap(cause, "m"), "m: gg", true},
+		{Wrapf(cause, "m%d", 1), "m1: gg", true},
+		{WithStack(cause), "gg", true},
+		{WithMessage(cause, "m"), "m: gg", false},
+		{WithMessagef(cause, "m%d", 1), "m1: gg", false},
+	}
+
+	for _, c := range cases {
+		t.Run(c.msg, func(t *testing.T) {
+			require.Equal(t, c.msg, c.err.Error())
+			require.Equal(t, cause, errors.Unwrap(c.err))
+			require.Equal(t, cause, Cause(c.err))
+			require.True(t, Is(c.err, cause))
+
+			st := c.err.(stackTracer).StackTrace()
+			if c.hasStack {
+				require.Equal(t, "TestWrap", fmt.Sprintf("%n", st[0]))
+			} else {
+				require.Nil(t, st)
+			}
+		})
+	}
+}
+
+func TestCause(t *testing.T) {
+	require.Nil(t, Cause(nil))
+
+	cause := errors.New("gg")
+	require.Equal(t, cause, Cause(cause))
+	require.Equal(t, cause, Cause(Wrap(WithMessage(cause, "a"), "b")))
+
+	// errx 的错误也有 Cause() 方法，其返回 nil 时即是最内层。
+	inner := errx.NewBizError(1, "biz", nil)
+	require.Equal(t, inner, Cause(Wrap(errx.Wrap("p", inner), "m")))
+
+	n := New("n")
+	require.Equal(t, n, Cause(n))
+}
+
+func TestFormat(t *testing.T) {
+	err := Wrap(errors.New("gg"), "m")
+	require.Equal(t, "m: gg", fmt.Sprintf("%s", err))
+	require.Equal(t, "m: gg", fmt.Sprintf("%v", err))
+	require.Equal(t, `"m: gg"`, fmt.Sprintf("%q", err))
+
+	// %+v 与 pkg/errors 相同：每行一个 Frame ，格式为“函数\n\t文件:行号”。
+	frame := `\n.+/go-errx/compat\.TestFormat\n\t.+/compat_test\.go:\d+\n`
+	require.Regexp(t, `^gg\nm`+frame, fmt.Sprintf("%+v", err))
+	require.Regexp(t, `^n`+frame, fmt.Sprintf("%+v", New("n")))
+	require.Regexp(t, `^gg`+frame, fmt.Sprintf("%+v", WithStack(errors.New("gg"))))
+	require.Equal(t, "gg\nm", fmt.Sprintf("%+v", WithMessage(errors.New("gg"), "m")))
+
+	// Cause 的调用栈也被输出。
+	require.Regexp(t, `^n`+frame+`(.+\n)*m`+frame, fmt.Sprintf("%+v", Wrap(New("n"), "m")))
+}
+
+func TestAsUnwrap(t *testing.T) {
+	biz := errx.NewBizError(1, "biz", nil)
+	err := Wrap(biz, "m")
+
+	var target errx.BizError
+	require.True(t, As(err, &target))
+	require.Equal(t, biz, target)
+	require.Equal(t, biz, Unwrap(err))
+}
@@ -0,0 +1,83 @@
+package errx
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+)
+
+// ContextExtractor 从 context.Context 中获取需要附加在错误上的键值对，如 trace ID 、租户 ID 等。
+// 可通过 RegisterContextExtractor() 注册，供 WrapCtx() 和 NewBizErrorCtx() 使用。
+type ContextExtractor func(ctx context.Context) []Field
+
+// ContextValueExtractor 返回一个 ContextExtractor ，它以 ctx.Value(key) 的值作为名为 name 的键值对。
+// 若 ctx.Value(key) 为 nil ，则不产生键值对。
+func ContextValueExtractor(name string, key interface{}) ContextExtractor {
+	return func(ctx context.Context) []Field {
+		v := ctx.Value(key)
+		if v == nil {
+			return nil
+		}
+		return []Field{{name, v}}
+	}
+}
+
+// RegisterContextExtractor 注册一个 ContextExtractor 。通常在程序初始化时调用。
+// 注册的顺序即键值对附加到错误上的顺序。
+func RegisterContextExtractor(f ContextExtractor) {
+	if f == nil {
+		return
+	}
+
+	contextExtractorsMu.Lock()
+	defer contextExtractorsMu.Unlock()
+
+	// 写时复制，读取时不需要加锁。
+	old, _ := contextExtractorsValue.Load().([]ContextExtractor)
+	extractors := make([]ContextExtractor, 0, len(old)+1)
+	extractors = append(extractors, old...)
+	extractors = append(extractors, f)
+	contextExtractorsValue.Store(extractors)
+}
+
+var (
+	contextExtractorsMu    sync.Mutex // 仅用于串行化写操作。
+	contextExtractorsValue atomic.Value
+)
+
+// contextFields 通过已注册的 ContextExtractor 从 ctx 中获取键值对。
+func contextFields(ctx context.Context) []Field {
+	if ctx == nil {
+		return nil
+	}
+
+	extractors, _ := contextExtractorsValue.Load().([]ContextExtractor)
+	var fields []Field
+	for _, f := range extractors {
+		fields = append(fields, f(ctx)...)
+	}
+	return fields
+}
+
+// WrapCtx 与 Wrap() 相同，同时通过已注册的 ContextExtractor 从 ctx 中获取键值对，附加在错误上。
+// ctx 可以为 nil ，此时不附加键值对。
+func WrapCtx(ctx context.Context, message string, cause error) StackfulError {
+	return &ErrorWrapper{
+		ErrorCause:  ErrorCause{cause},
+		ErrorStack:  captureStack(CaptureInfo{Kind: CaptureWrap, Cause: cause}, 3), // 调用栈不包括当前函数。
+		ErrorFields: ErrorFields{contextFields(ctx)},
+		msg:         message,
+	}
+}
+
+// NewBizErrorCtx 与 NewBizError() 相同，同时通过已注册的 ContextExtractor 从 ctx 中获取键值对，附加在错误上。
+// ctx 可以为 nil ，此时不附加键值对。
+func NewBizErrorCtx(ctx context.Context, code int, message string, cause error) BizError {
+	return &bizErr{
+		code:        code,
+		message:     message,
+		ErrorCause:  ErrorCause{cause},
+		ErrorStack:  captureStack(CaptureInfo{Kind: CaptureBizError, Cause: cause, Code: code}, 3), // 调用栈不包括当前函数。
+		ErrorFields: ErrorFields{contextFields(ctx)},
+	}
+}
@@ -0,0 +1,70 @@
+package errx
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type ctxKey string
+
+// resetContextExtractors 清除所有已注册的 ContextExtractor ，用于测试结束后恢复默认状态。
+func resetContextExtractors() {
+	contextExtractorsMu.Lock()
+	defer contextExtractorsMu.Unlock()
+	contextExtractorsValue.Store([]ContextExtractor(nil))
+}
+
+func TestContextValueExtractor(t *testing.T) {
+	f := ContextValueExtractor("trace", ctxKey("trace"))
+	require.Nil(t, f(context.Background()))
+	require.Equal(t, []Field{{"trace", "t1"}}, f(context.WithValue(context.Background(), ctxKey("trace"), "t1")))
+}
+
+func TestWrapCtx(t *testing.T) {
+	defer resetContextExtractors()
+
+	ctx := context.WithValue(context.Background(), ctxKey("trace"), "t1")
+	ctx = context.WithValue(ctx, ctxKey("tenant"), 7)
+
+	// 未注册时，没有键值对。
+	err := WrapCtx(ctx, "msg", nil)
+	require.Nil(t, Fields(err))
+
+	RegisterContextExtractor(ContextValueExtractor("trace", ctxKey("trace")))
+	RegisterContextExtractor(nil)
+	RegisterContextExtractor(func(ctx context.Context) []Field {
+		return []Field{{"tenant", ctx.Value(ctxKey("tenant"))}, {"fixed", true}}
+	})
+
+	cause := errors.New("cause")
+	err = WrapCtx(ctx, "msg", cause)
+	require.Equal(t, "msg: cause", err.ErrorWithoutStack())
+	require.Equal(t, cause, err.Cause())
+	require.Regexp(t, `^\[.+context_test\.go:\d+\] go-errx\.TestWrapCtx\n`, err.Stack())
+	require.Equal(t, []Field{{"trace", "t1"}, {"tenant", 7}, {"fixed", true}}, err.(FieldCarrier).Fields())
+	require.Regexp(t, `^msg: cause\n\+\+\+ trace=t1 tenant=7 fixed=true\n--- `, Describe(err))
+
+	// nil 的 context 不产生键值对。
+	var nilCtx context.Context
+	err = WrapCtx(nilCtx, "msg", nil)
+	require.Nil(t, Fields(err))
+}
+
+func TestNewBizErrorCtx(t *testing.T) {
+	defer resetContextExtractors()
+	RegisterContextExtractor(ContextValueExtractor("trace", ctxKey("trace")))
+
+	ctx := context.WithValue(context.Background(), ctxKey("trace"), "t1")
+	err := NewBizErrorCtx(ctx, 12, "biz", nil)
+	require.Equal(t, 12, err.Code())
+	require.Equal(t, "biz", err.Message())
+	require.Equal(t, "(12) biz", err.Error())
+	require.Regexp(t, `^\[.+context_test\.go:\d+\] go-errx\.TestNewBizErrorCtx\n`, err.Stack())
+	require.Equal(t, map[string]interface{}{"trace": "t1"}, Fields(Wrap("outer", err)))
+
+	// 结构化的输出也包含这些键值对。
+	require.Equal(t, map[string]interface{}{"trace": "t1"}, NewErrorChain(err)[0].Fields)
+}
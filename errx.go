@@ -2,21 +2,26 @@
 package errx
 
 import (
+	"encoding/json"
 	"errors"
 	"fmt"
-	"io"
-	"strconv"
-	"strings"
 )
 
 // ErrorWrapper 是一个 StackfulError ，封装另一个 error ，其表示引起当前错误的原因。
 type ErrorWrapper struct {
 	ErrorCause
 	ErrorStack
-	msg string
+	ErrorFields
+	msg      string
+	verbatim bool    // 为 true 时 msg 已包含内部错误的描述，见 Errorf() 。
+	wrapped  []error // Wrapf() 的格式中通过 %w 封装的错误，不包括 cause 。
 }
 
 var _ StackfulError = (*ErrorWrapper)(nil)
+var _ StackTracer = (*ErrorWrapper)(nil)
+var _ FieldCarrier = (*ErrorWrapper)(nil)
+var _ FieldAttacher = (*ErrorWrapper)(nil)
+var _ json.Marshaler = (*ErrorWrapper)(nil)
 var _ fmt.Formatter = (*ErrorWrapper)(nil)
 
 // Error 返回以 Describe() 的格式输出错误信息。
@@ -31,22 +36,44 @@ func (w *ErrorWrapper) Error() string {
 //   - 若 cause 为 nil，则仅返回当前实例的错误信息；
 //   - 若 cause 为 StackfulError， 则返回： message: cause.ErrorWithoutStack() ；
 //   - 若 cause 不是 StackfulError， 则返回： message: cause.Error() 。
+//
+// 由 Errorf() 或 Wrapf() 通过 %w 封装内部错误时，错误信息本身已包含内部错误的描述，直接返回。
+//
+// 错误链中的环以“<cycle detected>”代替，超过 DefaultMaxDepth 限制的部分以“<truncated N more>”代替。
 func (w *ErrorWrapper) ErrorWithoutStack() string {
-	c := w.Cause()
-	if c == nil {
-		return w.msg
-	}
+	return messageWithoutStack(w)
+}
+
+// With 实现 FieldAttacher.With() 。
+func (w *ErrorWrapper) With(key string, value interface{}) StackfulError {
+	res := *w
+	res.ErrorFields = w.ErrorFields.with(Field{key, value})
+	return &res
+}
+
+// Wrapped 返回 Wrapf() 的格式中通过 %w 封装的错误，不包括 Cause() 。没有时返回 nil 。返回值不应被修改。
+func (w *ErrorWrapper) Wrapped() []error {
+	return w.wrapped
+}
 
-	prefix := w.msg
-	if w.msg != "" {
-		prefix += ": "
+// Is 供 errors.Is() 使用，判断 Wrapped() 中是否有与 target 匹配的错误。 Cause() 由 errors.Is() 通过 Unwrap() 检查。
+func (w *ErrorWrapper) Is(target error) bool {
+	for _, e := range w.wrapped {
+		if errors.Is(e, target) {
+			return true
+		}
 	}
+	return false
+}
 
-	se, ok := w.Cause().(StackfulError)
-	if ok {
-		return prefix + se.ErrorWithoutStack()
+// As 供 errors.As() 使用，在 Wrapped() 中查找可以赋值给 target 的错误。 Cause() 由 errors.As() 通过 Unwrap() 检查。
+func (w *ErrorWrapper) As(target interface{}) bool {
+	for _, e := range w.wrapped {
+		if errors.As(e, target) {
+			return true
+		}
 	}
-	return prefix + c.Error()
+	return false
 }
 
 // Format 实现 fmt.Formatter.Formats() 。
@@ -54,28 +81,24 @@ func (w *ErrorWrapper) ErrorWithoutStack() string {
 //
 //	%s      输出 ErrorWithoutStack()
 //	%q      输出 strconv.Quote(ErrorWithoutStack())
-//	%v/%+v  输出 Error()
+//	%v      输出 Error() ，即 Describe() 的结果
+//	%+v     输出 Describe() 的结果
+//	%#v     输出 Go 语法风格的结构，用于调试
 //	other   输出 BADFORMAT: ErrorWithoutStack()
+//
+// errx 的其他 StackfulError 遵循相同的规则，但 BizError 的 Error() 不含调用栈，并额外支持以 %d 、 %x 等输出错误码。
 func (w *ErrorWrapper) Format(f fmt.State, verb rune) {
-	var out string
-
-	switch verb {
-	case 's':
-		out = w.ErrorWithoutStack()
-	case 'q':
-		out = strconv.Quote(w.ErrorWithoutStack())
-	case 'v':
-		out = w.Error()
-	default:
-		// 其他不支持的格式，输出： BADFORMAT:Message()
-		out = "BADFORMAT:" + w.ErrorWithoutStack()
-	}
+	formatStackful(f, verb, w)
+}
 
-	io.WriteString(f, out)
+// MarshalJSON 实现 json.Marshaler ，输出整个错误链，格式见 ErrorChain 。
+func (w *ErrorWrapper) MarshalJSON() ([]byte, error) {
+	return MarshalChain(w)
 }
 
 // Wrap 封装给定的 error ，返回 StackfulError 。
 // 错误信息的格式为： message: cause.Error() 。若 cause 为 nil，则仅返回 message  。
+// 是否记录调用栈由调用栈记录策略决定，默认总是记录，见 SetCapturePolicy() 。
 //
 // 得到的 StackfulError.Stack() 有一个固定的开头“--- ”，末尾会有一个空行。格式为：
 //
@@ -83,7 +106,7 @@ func (w *ErrorWrapper) Format(f fmt.State, verb rune) {
 func Wrap(message string, cause error) StackfulError {
 	return &ErrorWrapper{
 		ErrorCause: ErrorCause{cause},
-		ErrorStack: GetErrorStack(3), // 调用栈不包括当前函数。
+		ErrorStack: captureStack(CaptureInfo{Kind: CaptureWrap, Cause: cause}, 3), // 调用栈不包括当前函数。
 		msg:        message,
 	}
 }
@@ -97,34 +120,130 @@ func WrapWithoutStack(message string, cause error) StackfulError {
 	}
 }
 
-// PreserveRecover 用于封装从 panic 中 recover 的数据，返回 StackfulError 。
-// 此方法的调用应放在 defer 过程里。
-func PreserveRecover(message string, recovered interface{}) StackfulError {
-	if recovered == nil {
-		return nil
-	}
+// Wrapf 与 Wrap() 相同，但以 fmt.Errorf() 的规则格式化 message 。错误信息的格式为： message: cause.Error() 。
+//
+// 若 cause 为 nil ，则与 Errorf() 相同，通过 %w 封装的错误即是内部错误；
+// 否则 Cause() 和 errors.Unwrap() 总是 cause ，错误链沿 cause 延续。格式中通过 %w 封装的错误可通过
+// ErrorWrapper.Wrapped() 获取，也可被 errors.Is() 、 errors.As() 找到，但不属于错误链，不在 Describe() 等中展示。
+func Wrapf(cause error, format string, args ...interface{}) StackfulError {
+	return wrapFormatted(cause, format, args)
+}
+
+// Errorf 以 fmt.Errorf() 的规则格式化错误信息，返回记录了调用栈的 StackfulError 。
+// 格式中通过 %w 封装的错误即是其内部错误，可通过 Cause() 和 errors.Unwrap() 获取，错误信息即是格式化的结果：
+//
+//	err := errx.Errorf("load %s: %w", name, cause) // err.Cause() == cause
+//
+// 与 fmt.Errorf() 一样，自 Go 1.20 起支持多个 %w ，此时得到的错误实现 Unwrap() []error ，
+// 各个被封装的错误可通过 errors.Is() 、 errors.As() 找到，其 Cause() 为 nil 。 Describe() 将它们作为分支逐个展示。
+// 是否记录调用栈由调用栈记录策略决定，默认总是记录，见 SetCapturePolicy() 。
+func Errorf(format string, args ...interface{}) StackfulError {
+	return wrapFormatted(nil, format, args)
+}
+
+// wrapFormatted 实现 Wrapf() 和 Errorf() 。
+func wrapFormatted(cause error, format string, args []interface{}) StackfulError {
+	msg, wrapped := formatWrapped(format, args...)
 
-	var cause error
-	switch e := recovered.(type) {
-	case error:
-		cause = e
-	case string:
-		cause = fmt.Errorf(e)
-	default:
-		// panic 的不是 error 和字符串也应该是个能转成字符串的东西。
-		cause = fmt.Errorf("%v", e)
+	if cause == nil && len(wrapped) > 1 {
+		return &wrapErrors{
+			ErrorStack: captureStack(CaptureInfo{Kind: CaptureWrap}, 4), // 调用栈不包括当前函数和 Errorf() 等。
+			msg:        msg,
+			errs:       wrapped,
+		}
 	}
 
-	return &ErrorWrapper{
+	w := &ErrorWrapper{
 		ErrorCause: ErrorCause{cause},
-		ErrorStack: GetErrorStack(4), // 忽略当前函数、 panic 调用和 defer 的函数。
-		msg:        message,
+		msg:        msg,
+		wrapped:    wrapped,
+	}
+	if cause == nil && len(wrapped) == 1 {
+		w.ErrorCause = ErrorCause{wrapped[0]}
+		w.verbatim = true
+		w.wrapped = nil
+	}
+	w.ErrorStack = captureStack(CaptureInfo{Kind: CaptureWrap, Cause: w.Err}, 4) // 调用栈不包括当前函数和 Wrapf() 等。
+	return w
+}
+
+// formatWrapped 通过 fmt.Errorf() 格式化，返回得到的错误信息和通过 %w 封装的错误。
+func formatWrapped(format string, args ...interface{}) (string, []error) {
+	err := fmt.Errorf(format, args...)
+
+	var wrapped []error
+	switch e := err.(type) {
+	case interface{ Unwrap() error }:
+		if inner := e.Unwrap(); inner != nil {
+			wrapped = []error{inner}
+		}
+	case interface{ Unwrap() []error }:
+		for _, inner := range e.Unwrap() {
+			if inner != nil {
+				wrapped = append(wrapped, inner)
+			}
+		}
 	}
+	return err.Error(), wrapped
+}
+
+// wrapErrors 是 Errorf() （或 cause 为 nil 的 Wrapf() ）的格式中有多个 %w 时得到的错误。
+// 它实现 Unwrap() []error ，与 Multi 一样，各个错误在 Describe() 中作为分支展示。
+type wrapErrors struct {
+	ErrorStack
+	ErrorFields
+	msg  string
+	errs []error
+}
+
+var _ StackfulError = (*wrapErrors)(nil)
+var _ StackTracer = (*wrapErrors)(nil)
+var _ FieldCarrier = (*wrapErrors)(nil)
+var _ FieldAttacher = (*wrapErrors)(nil)
+var _ json.Marshaler = (*wrapErrors)(nil)
+var _ fmt.Formatter = (*wrapErrors)(nil)
+
+// Error 返回以 Describe() 的格式输出错误信息，每个被封装的错误作为一个分支展示。
+func (e *wrapErrors) Error() string {
+	return Describe(e)
+}
+
+// ErrorWithoutStack 实现 StackfulError.ErrorWithoutStack() ，即格式化的结果，其中已包含各个被封装的错误的描述。
+func (e *wrapErrors) ErrorWithoutStack() string {
+	return e.msg
+}
+
+// Cause 实现 StackfulError.Cause() 。被封装的错误有多个，没有单一的 Cause ，总是返回 nil 。
+func (e *wrapErrors) Cause() error {
+	return nil
+}
+
+// Unwrap 返回所有被封装的错误，以支持 errors.Is() 和 errors.As() （ Go 1.20 及以上）。返回值不应被修改。
+func (e *wrapErrors) Unwrap() []error {
+	return e.errs
+}
+
+// With 实现 FieldAttacher.With() 。
+func (e *wrapErrors) With(key string, value interface{}) StackfulError {
+	res := *e
+	res.ErrorFields = e.ErrorFields.with(Field{key, value})
+	return &res
+}
+
+// Format 实现 fmt.Formatter.Formats() ，规则与 ErrorWrapper.Format() 相同。
+func (e *wrapErrors) Format(f fmt.State, verb rune) {
+	formatStackful(f, verb, e)
+}
+
+// MarshalJSON 实现 json.Marshaler ，输出整个错误链，格式见 ErrorChain 。
+func (e *wrapErrors) MarshalJSON() ([]byte, error) {
+	return MarshalChain(e)
 }
 
 // Describe 返回一个字符串描述给定的错误。如果给定 nil ，返回空字符串。
 //
-// 递归使用 errors.Unwrap() 获取内部错误，并追加在描述信息上。如果错误是 StackfulError ，则描述携带调用栈信息。
+// 递归使用 errors.Unwrap() 获取内部错误，并追加在描述信息上。如果错误是 StackfulError ，则描述携带调用栈信息；
+// 其他实现 StackTracer 或有 pkg/errors 风格的 StackTrace() 方法的错误，也会输出其调用栈。
 // 若不能获取到对应的信息，则该部分省略。
 //
 // 可通过此方法获取完整的错误链信息。
@@ -141,52 +260,45 @@ func PreserveRecover(message string, recovered interface{}) StackfulError {
 //	=== 最内层错误的描述
 //	--- 最内层错误的描述的调用栈信息
 //
+// 若某一层错误带有键值对（见 FieldCarrier ），则在其描述之后、调用栈之前输出一行，格式为：
+//
+//	+++ key1=value1 key2=value2
+//
+// 若某一层错误实现了 Unwrap() []error （如 errors.Join() 得到的错误），则其每个内部错误作为一个分支，
+// 以“=== [序号/总数] ”开头逐个输出，分支内的其余行被缩进。分支内的错误同样逐层展示：
+//
+//	外层错误描述
+//	--- 外层错误的调用栈信息
+//	=== 多个错误的描述
+//	=== [1/2] 第1个分支的错误描述
+//	    --- 第1个分支的错误的调用栈信息
+//	    === 第1个分支的内部错误的描述
+//	=== [2/2] 第2个分支的错误描述
+//	    --- 第2个分支的错误的调用栈信息
+//
+// 内层错误的调用栈通常与外层的重叠（如都包含 main 函数等最外层的调用）。
+// 若错误实现了 StackTracer ，则其与上一个输出的调用栈末尾相同的部分被省略，以一行“... N frames in common with above”代替。
+//
+// 错误链中的环（某一层的内部错误是其外层的错误）以一行“<cycle detected>”代替重复的错误；
+// 超过 Printer.MaxDepth 限制（默认为 DefaultMaxDepth ）的内层错误以一行“<truncated N more>”代替。
+//
 // 末尾总是一个空行。
+//
+// 此方法使用 NewPrinter() 得到的默认设置，若需要调整输出的内容和格式，可使用 Printer 。
 func Describe(err error) string {
-	if err == nil {
-		return ""
-	}
-
-	var msg strings.Builder
-	for {
-		if msg.Len() > 0 {
-			msg.WriteString("=== ")
-		}
-
-		var buf string
-
-		switch e := err.(type) {
-		case StackfulError:
-			msg.WriteString(e.ErrorWithoutStack())
-			msg.WriteString("\n--- ")
-			buf = e.Stack()
-
-		default:
-			buf = e.Error()
-		}
-
-		if len(buf) > 0 {
-			msg.WriteString(buf)
-
-			if buf[len(buf)-1] != '\n' {
-				msg.WriteRune('\n')
-			}
-		}
-
-		err = errors.Unwrap(err)
-		if err == nil {
-			break
-		}
-	}
-
-	return msg.String()
+	return defaultPrinter.Describe(err)
 }
 
 // 执行给定的函数。
-// 若函数成功执行，返回 nil ；若函数 panic ，则通过 [PreserveRecover] 捕获并返回对应的错误。
-func Run(f func()) (err error) {
+// 若函数成功执行，返回 nil ；若函数 panic ，则通过 [PreserveRecover] 捕获并返回对应的 *PanicError 。
+func Run(f func()) error {
+	return run(capturePackage(3), f) // 包级别的策略按调用 Run() 的函数所在的包判断。
+}
+
+// run 实现 Run() ， pkg 是判断包级别的调用栈记录策略所用的包路径，见 capturePackage() 。
+func run(pkg string, f func()) (err error) {
 	defer func() {
-		err = PreserveRecover("", recover())
+		err = recoverPanic("", recover(), pkg, 3)
 	}()
 
 	f()
@@ -194,11 +306,16 @@ func Run(f func()) (err error) {
 }
 
 // 执行带有一个 error 返回值的的函数。
-// 若函数成功执行，返回函数的返回值；若函数 panic ，则通过 [PreserveRecover] 捕获并返回对应的错误。
-func RunE(f func() error) (err error) {
+// 若函数成功执行，返回函数的返回值；若函数 panic ，则通过 [PreserveRecover] 捕获并返回对应的 *PanicError 。
+func RunE(f func() error) error {
+	return runE(capturePackage(3), f) // 包级别的策略按调用 RunE() 的函数所在的包判断。
+}
+
+// runE 实现 RunE() ， pkg 的含义同 run() 。
+func runE(pkg string, f func() error) (err error) {
 	defer func() {
 		if err == nil {
-			err = PreserveRecover("", recover())
+			err = recoverPanic("", recover(), pkg, 3)
 		}
 	}()
 
@@ -0,0 +1,50 @@
+//go:build go1.20
+
+package errx
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestErrorf_MultipleW(t *testing.T) {
+	e1 := errors.New("e1")
+	e2 := NewBizError(2, "biz", nil)
+	err := Errorf("a: %w, b: %w", e1, e2)
+
+	require.Equal(t, "a: e1, b: (2) biz", err.ErrorWithoutStack())
+	require.True(t, errors.Is(err, e1))
+	require.True(t, errors.Is(err, e2))
+
+	var biz BizError
+	require.True(t, errors.As(err, &biz))
+	require.Equal(t, 2, biz.Code())
+
+	require.Nil(t, err.Cause())
+	multi, ok := err.(interface{ Unwrap() []error })
+	require.True(t, ok)
+	require.Equal(t, []error{e1, e2}, multi.Unwrap())
+	require.Equal(t, "github.com/cmstar/go-errx.TestErrorf_MultipleW", err.(StackTracer).Frames()[0].Function)
+
+	// 各个错误作为分支展示，错误信息不重复输出。
+	require.Regexp(t, `^a: e1, b: \(2\) biz\n--- .+\n(.+\n)*=== \[1/2\] e1\n=== \[2/2\] \(2\) biz\n    --- `, Describe(err))
+	require.Equal(t, 1, strings.Count(Describe(err), "a: e1"))
+}
+
+func TestWrapf_MultipleW(t *testing.T) {
+	cause := errors.New("gg")
+	e1 := errors.New("e1")
+	e2 := errors.New("e2")
+	err := Wrapf(cause, "a: %w, b: %w", e1, e2)
+
+	require.Equal(t, "a: e1, b: e2: gg", err.ErrorWithoutStack())
+	require.Equal(t, cause, err.Cause())
+	require.Equal(t, cause, errors.Unwrap(err))
+	require.Equal(t, []error{e1, e2}, err.(*ErrorWrapper).Wrapped())
+	require.True(t, errors.Is(err, cause))
+	require.True(t, errors.Is(err, e1))
+	require.True(t, errors.Is(err, e2))
+}
@@ -146,6 +146,35 @@ func TestDescribe(t *testing.T) {
 			})
 	})
 
+	t.Run("common-frames", func(t *testing.T) {
+		inner := func() error {
+			return Wrap("inner", errors.New("gg"))
+		}
+		cause := inner()
+		res := Describe(Wrap("outer", cause))
+
+		// 内层的调用栈仅保留 inner 函数和调用它的一行，其余与外层重叠。
+		require.Regexp(t, `=== inner: gg\n--- \[.+errx_test\.go:\d+\] go-errx\.TestDescribe\.func\d+\.1\n`+
+			`\[.+errx_test\.go:\d+\] go-errx\.TestDescribe\.func\d+\n`+
+			`\.\.\. \d+ frames in common with above\n=== gg`, res)
+	})
+
+	t.Run("same-line", func(t *testing.T) {
+		res := Describe(Wrap("pre1", Wrap("pre2", errors.New("gg"))))
+
+		// 在同一行创建的错误，调用栈完全相同，但至少保留第一行。
+		require.Regexp(t, `=== pre2: gg\n--- \[.+errx_test\.go:\d+\] go-errx\.TestDescribe\.func\d+\n`+
+			`\.\.\. \d+ frames in common with above\n=== gg`, res)
+	})
+
+	t.Run("without-stack", func(t *testing.T) {
+		check(t,
+			WrapWithoutStack("pre1", NewBizErrorWithoutStack(100, "biz", errors.New("gg"))),
+			[]string{
+				`^pre1: \(100\) biz\n=== \(100\) biz\n=== gg\n$`,
+			})
+	})
+
 	t.Run("biz-wrap", func(t *testing.T) {
 		check(t,
 			NewBizError(100, "biz", Wrap("inner", errors.New("gg"))),
@@ -293,3 +322,80 @@ func TestRunE(t *testing.T) {
 		require.Contains(t, err.Error(), "some error")
 	})
 }
+
+func TestErrorf(t *testing.T) {
+	t.Run("no-w", func(t *testing.T) {
+		err := Errorf("a %d", 1)
+		require.Equal(t, "a 1", err.ErrorWithoutStack())
+		require.Nil(t, err.Cause())
+		require.Equal(t, "github.com/cmstar/go-errx.TestErrorf.func1", err.(StackTracer).Frames()[0].Function)
+	})
+
+	t.Run("w", func(t *testing.T) {
+		cause := NewBizError(1, "biz", nil)
+		err := Errorf("load %s: %w", "x", cause)
+		require.Equal(t, "load x: (1) biz", err.ErrorWithoutStack())
+		require.Equal(t, cause, err.Cause())
+		require.Equal(t, cause, errors.Unwrap(err))
+		require.Equal(t, "github.com/cmstar/go-errx.TestErrorf.func2", err.(StackTracer).Frames()[0].Function)
+		require.Regexp(t, `^load x: \(1\) biz\n--- .+\n(.+\n)*=== \(1\) biz\n`, Describe(err))
+	})
+
+	t.Run("wrap-errorf", func(t *testing.T) {
+		err := Wrap("p", Errorf("a: %w", errors.New("gg")))
+		require.Equal(t, "p: a: gg", err.ErrorWithoutStack())
+	})
+}
+
+func TestWrapf(t *testing.T) {
+	t.Run("cause", func(t *testing.T) {
+		cause := errors.New("gg")
+		err := Wrapf(cause, "load %s", "x")
+		require.Equal(t, "load x: gg", err.ErrorWithoutStack())
+		require.Equal(t, cause, err.Cause())
+		require.Equal(t, "github.com/cmstar/go-errx.TestWrapf.func1", err.(StackTracer).Frames()[0].Function)
+	})
+
+	t.Run("cause-and-w", func(t *testing.T) {
+		cause := errors.New("gg")
+		other := errors.New("other")
+		err := Wrapf(cause, "a %w", other)
+		require.Equal(t, "a other: gg", err.ErrorWithoutStack())
+		require.Equal(t, cause, err.Cause())
+		require.Equal(t, "github.com/cmstar/go-errx.TestWrapf.func2", err.(StackTracer).Frames()[0].Function)
+
+		// 错误链沿 cause 延续， %w 封装的错误可通过 Wrapped() 获取，也可被 errors.Is() 、 errors.As() 找到。
+		require.Equal(t, cause, errors.Unwrap(err))
+		require.True(t, errors.Is(err, cause))
+		require.True(t, errors.Is(err, other))
+		require.Equal(t, []error{other}, err.(*ErrorWrapper).Wrapped())
+		require.Equal(t, cause, RootCause(Wrap("p", err)))
+		require.Equal(t, []error{err, cause}, Chain(err))
+		require.Regexp(t, `^a other: gg\n--- .+\n(.+\n)*=== gg\n$`, Describe(err))
+		require.Equal(t, "p: a other: gg", Wrap("p", err).ErrorWithoutStack())
+
+		biz := NewBizError(3, "biz", nil)
+		var target BizError
+		require.True(t, errors.As(Wrapf(cause, "a %w", biz), &target))
+		require.Equal(t, biz, target)
+
+		// cause 上的键值对同样可以获取。
+		require.Equal(t, map[string]interface{}{"k": 1}, Fields(Wrapf(WrapWithFields("m", nil, Field{"k", 1}), "a %w", other)))
+
+		err = Wrapf(cause, "a %w", nil)
+		require.IsType(t, (*ErrorWrapper)(nil), err)
+		require.Equal(t, "a %!w(<nil>): gg", err.ErrorWithoutStack())
+		require.Equal(t, cause, errors.Unwrap(err))
+	})
+
+	t.Run("nil-cause", func(t *testing.T) {
+		err := Wrapf(nil, "a %d", 1)
+		require.Equal(t, "a 1", err.ErrorWithoutStack())
+		require.Nil(t, err.Cause())
+
+		inner := errors.New("gg")
+		err = Wrapf(nil, "a: %w", inner)
+		require.Equal(t, "a: gg", err.ErrorWithoutStack())
+		require.Equal(t, inner, err.Cause())
+	})
+}
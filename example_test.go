@@ -4,6 +4,7 @@ import (
 	"bufio"
 	"errors"
 	"fmt"
+	"regexp"
 	"strings"
 
 	"github.com/cmstar/go-errx"
@@ -30,7 +31,7 @@ func Example_errorChain() {
 	// === from B: the original error
 	// --- go-errx_test.B
 	// go-errx_test.A
-	// go-errx_test.Example_errorChain
+	// ... N frames in common with above
 	// === the original error
 }
 
@@ -63,6 +64,7 @@ func simplify(stack string) string {
 		  [_testmain.go:57] main.main
 
 		过于冗长，耦合物理路径难以断言输出，将其简化，仅留下方法名，并去掉非本地代码的部分。
+		与外层重叠的调用栈的数量取决于 testing 包的实现，也将其替换掉。
 	*/
 	s := bufio.NewScanner(strings.NewReader(stack))
 	b := new(strings.Builder)
@@ -72,6 +74,10 @@ func simplify(stack string) string {
 			continue
 		}
 
+		if strings.HasPrefix(line, "... ") {
+			line = regexp.MustCompile(`\d+`).ReplaceAllString(line, "N")
+		}
+
 		idx := strings.Index(line, "[")
 		if idx >= 0 {
 			idxSlash := strings.LastIndex(line, " ")
@@ -0,0 +1,144 @@
+package errx
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Field 是附加在错误上的一个键值对，如请求 ID 、用户 ID 等，用于避免将这些数据拼接到错误描述中。
+type Field struct {
+	Key   string
+	Value interface{}
+}
+
+// FieldCarrier 是携带键值对的错误。 Wrap() 、 NewBizError() 等创建的错误都实现此接口。
+// 要获取整个错误链上的键值对，使用 Fields() 函数。
+type FieldCarrier interface {
+	// Fields 返回当前这一层错误上的键值对，按添加的顺序排列，不包含内部错误的键值对。
+	Fields() []Field
+}
+
+// FieldAttacher 是可以附加键值对的 StackfulError 。 Wrap() 、 PreserveRecover() 等创建的错误都实现此接口，
+// BizError 则实现 BizFieldAttacher 。通常使用 With() 函数，不必直接使用此接口。
+type FieldAttacher interface {
+	StackfulError
+
+	// With 返回附加了给定键值对的错误，不修改当前实例。返回的错误与当前实例的类型相同。
+	With(key string, value interface{}) StackfulError
+}
+
+// BizFieldAttacher 是可以附加键值对的 BizError ，与 FieldAttacher 相同，但 With() 返回的仍是 BizError 。
+// NewBizError() 等创建的 BizError 都实现此接口。
+type BizFieldAttacher interface {
+	BizError
+
+	// With 返回附加了给定键值对的 BizError ，不修改当前实例。
+	With(key string, value interface{}) BizError
+}
+
+// With 返回附加了给定键值对的错误，不修改 err 。若 err 为 nil ，返回 nil 。键值对可通过 Fields() 函数获取。
+//
+// 若 err 实现 FieldAttacher 或 BizFieldAttacher ，返回其 With() 的结果，类型与 err 相同；
+// 否则通过 WrapWithoutStack() 封装 err ，在外层附加键值对，错误描述不变。
+func With(err error, key string, value interface{}) StackfulError {
+	switch e := err.(type) {
+	case nil:
+		return nil
+	case FieldAttacher:
+		return e.With(key, value)
+	case BizFieldAttacher:
+		return e.With(key, value)
+	}
+
+	return &ErrorWrapper{
+		ErrorCause:  ErrorCause{err},
+		ErrorFields: ErrorFields{}.with(Field{key, value}),
+	}
+}
+
+// ErrorFields 用于存放附加在错误上的键值对，以便实现 FieldCarrier 。
+type ErrorFields struct {
+	fields []Field
+}
+
+// Fields 实现 FieldCarrier.Fields() 。返回的是一个副本，对其修改不影响 ErrorFields 。
+func (e ErrorFields) Fields() []Field {
+	if len(e.fields) == 0 {
+		return nil
+	}
+
+	res := make([]Field, len(e.fields))
+	copy(res, e.fields)
+	return res
+}
+
+// with 返回追加了给定键值对的新的 ErrorFields ，不修改当前实例。
+func (e ErrorFields) with(fields ...Field) ErrorFields {
+	if len(fields) == 0 {
+		return e
+	}
+
+	res := make([]Field, 0, len(e.fields)+len(fields))
+	res = append(res, e.fields...)
+	res = append(res, fields...)
+	return ErrorFields{res}
+}
+
+// WrapWithFields 与 Wrap() 相同，同时在错误上附加给定的键值对。
+func WrapWithFields(message string, cause error, fields ...Field) StackfulError {
+	return &ErrorWrapper{
+		ErrorCause:  ErrorCause{cause},
+		ErrorStack:  captureStack(CaptureInfo{Kind: CaptureWrap, Cause: cause}, 3), // 调用栈不包括当前函数。
+		ErrorFields: ErrorFields{}.with(fields...),
+		msg:         message,
+	}
+}
+
+// Fields 获取整个错误链上的键值对。通过 errors.Unwrap() 逐层获取实现了 FieldCarrier 的错误，合并其键值对。
+// 若不同层的错误有相同的键，外层的值优先；同一层中有相同的键，后添加的值优先。
+// 若没有任何键值对，返回 nil 。
+func Fields(err error) map[string]interface{} {
+	var layers [][]Field
+	for _, err := range Chain(err) {
+		if fc, ok := err.(FieldCarrier); ok {
+			if fs := fc.Fields(); len(fs) > 0 {
+				layers = append(layers, fs)
+			}
+		}
+	}
+
+	if len(layers) == 0 {
+		return nil
+	}
+
+	// 从内层开始赋值，外层的值覆盖内层的。
+	res := make(map[string]interface{})
+	for i := len(layers) - 1; i >= 0; i-- {
+		for _, f := range layers[i] {
+			res[f.Key] = f.Value
+		}
+	}
+	return res
+}
+
+// formatFields 将键值对输出为一行，格式为： key1=value1 key2=value2 。
+// 值通过 fmt.Sprint() 转换为字符串，若其为空或包含空白、引号、“=”，则以 strconv.Quote() 转义。
+func formatFields(fields []Field) string {
+	var b strings.Builder
+	for i, f := range fields {
+		if i > 0 {
+			b.WriteRune(' ')
+		}
+
+		b.WriteString(f.Key)
+		b.WriteRune('=')
+
+		v := fmt.Sprint(f.Value)
+		if v == "" || strings.ContainsAny(v, " \t\r\n\"=") {
+			v = strconv.Quote(v)
+		}
+		b.WriteString(v)
+	}
+	return b.String()
+}
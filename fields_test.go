@@ -0,0 +1,146 @@
+package errx
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestWrapWithFields(t *testing.T) {
+	cause := errors.New("cause")
+	got := WrapWithFields("msg", cause, Field{"a", 1}, Field{"b", "x"})
+
+	a := require.New(t)
+	a.Equal("msg: cause", got.ErrorWithoutStack())
+	a.Equal(cause, got.Cause())
+	a.Regexp(`^\[.+fields_test\.go:\d+\] go-errx\.TestWrapWithFields\n`, got.Stack())
+	a.Equal([]Field{{"a", 1}, {"b", "x"}}, got.(FieldCarrier).Fields())
+
+	a.Nil(WrapWithFields("msg", nil).(FieldCarrier).Fields())
+}
+
+func TestWith(t *testing.T) {
+	t.Run("nil", func(t *testing.T) {
+		require.Nil(t, With(nil, "a", 1))
+	})
+
+	t.Run("wrapper", func(t *testing.T) {
+		origin := Wrap("msg", nil)
+		got := With(With(origin, "a", 1), "b", 2)
+		require.IsType(t, &ErrorWrapper{}, got)
+		require.Equal(t, []Field{{"a", 1}, {"b", 2}}, got.(FieldCarrier).Fields())
+		require.Equal(t, origin.Stack(), got.Stack())
+		require.Equal(t, "msg", got.ErrorWithoutStack())
+
+		// 不修改原实例。
+		require.Nil(t, origin.(FieldCarrier).Fields())
+	})
+
+	t.Run("branch", func(t *testing.T) {
+		// 从同一个实例派生的错误互不影响。
+		base := With(Wrap("msg", nil), "a", 1)
+		x := With(base, "x", 1)
+		y := With(base, "y", 2)
+		require.Equal(t, []Field{{"a", 1}, {"x", 1}}, x.(FieldCarrier).Fields())
+		require.Equal(t, []Field{{"a", 1}, {"y", 2}}, y.(FieldCarrier).Fields())
+	})
+
+	t.Run("biz", func(t *testing.T) {
+		// BizFieldAttacher.With() 返回的仍是 BizError 。
+		got := NewBizError(1, "biz", nil).(BizFieldAttacher).With("a", 1)
+		require.Equal(t, 1, got.Code())
+		require.Equal(t, []Field{{"a", 1}}, got.(FieldCarrier).Fields())
+
+		biz, ok := With(got, "b", 2).(BizError)
+		require.True(t, ok)
+		require.Equal(t, []Field{{"a", 1}, {"b", 2}}, biz.(FieldCarrier).Fields())
+	})
+
+	t.Run("other", func(t *testing.T) {
+		// 其他错误被封装，错误描述不变。
+		cause := errors.New("gg")
+		got := With(cause, "a", 1)
+		require.Equal(t, "gg", got.ErrorWithoutStack())
+		require.Equal(t, cause, got.Cause())
+		require.Equal(t, "", got.Stack())
+		require.Equal(t, map[string]interface{}{"a": 1}, Fields(got))
+	})
+
+	t.Run("multi", func(t *testing.T) {
+		origin := NewMulti("msg").Append(errors.New("a"))
+		got := origin.With("k", "v").(*Multi)
+		got.Append(errors.New("b"))
+		require.Equal(t, 1, origin.Len())
+		require.Equal(t, 2, got.Len())
+		require.Equal(t, []Field{{"k", "v"}}, got.Fields())
+	})
+
+	t.Run("remote", func(t *testing.T) {
+		code := 3
+		chain := ErrorChain{{Message: "a"}, {Message: "(3) b", Code: &code, BizMessage: "b"}}
+		got := With(chain.Err(), "k", "v")
+		require.IsType(t, &RemoteError{}, got)
+		require.Equal(t, []Field{{"k", "v"}}, got.(FieldCarrier).Fields())
+
+		got = With(got.Cause(), "k", "v")
+		require.IsType(t, &RemoteBizError{}, got)
+		require.Equal(t, 3, got.(BizError).Code())
+		require.Equal(t, []Field{{"k", "v"}}, got.(FieldCarrier).Fields())
+	})
+}
+
+func TestFields(t *testing.T) {
+	require.Nil(t, Fields(nil))
+	require.Nil(t, Fields(errors.New("e")))
+	require.Nil(t, Fields(Wrap("msg", nil)))
+
+	inner := WrapWithFields("inner", NewBizError(1, "biz", nil), Field{"a", "inner"}, Field{"b", 1}, Field{"b", 2})
+	err := With(With(Wrap("outer", fmt.Errorf("mid: %w", inner)), "a", "outer"), "c", nil)
+
+	require.Equal(t, map[string]interface{}{
+		"a": "outer",
+		"b": 2,
+		"c": nil,
+	}, Fields(err))
+}
+
+func TestFormatFields(t *testing.T) {
+	require.Equal(t, "", formatFields(nil))
+	require.Equal(t,
+		`a=1 b=x c="" d="x y" e="a=b" f="\"q\"" g=<nil> h="[1 2]"`,
+		formatFields([]Field{{"a", 1}, {"b", "x"}, {"c", ""}, {"d", "x y"}, {"e", "a=b"}, {"f", `"q"`}, {"g", nil}, {"h", []int{1, 2}}}))
+}
+
+func TestDescribe_fields(t *testing.T) {
+	err := With(WrapWithoutStack("outer", With(NewBizError(1, "biz", errors.New("gg")), "user", "bob")), "req", 12)
+	res := Describe(err)
+	require.Regexp(t, `^outer: \(1\) biz\n\+\+\+ req=12\n=== \(1\) biz\n\+\+\+ user=bob\n--- \[.+fields_test\.go:\d+\] go-errx\.TestDescribe_fields\n`, res)
+
+	p := NewPrinter()
+	p.OmitFields = true
+	p.OmitStack = true
+	require.Equal(t, "outer: (1) biz\n=== (1) biz\n=== gg\n", p.Describe(err))
+
+	p = NewPrinter()
+	p.OmitStack = true
+	p.FieldsPrefix = "fields: "
+	require.Equal(t, "outer: (1) biz\nfields: req=12\n=== (1) biz\nfields: user=bob\n=== gg\n", p.Describe(err))
+}
+
+func TestErrorChain_fields(t *testing.T) {
+	err := With(With(With(WrapWithoutStack("outer", nil), "a", 1), "b", "x"), "a", 2)
+	chain := NewErrorChain(err)
+	require.Equal(t, map[string]interface{}{"a": 2, "b": "x"}, chain[0].Fields)
+
+	data, e := MarshalChain(err)
+	require.NoError(t, e)
+	require.JSONEq(t, `[{"message":"outer","type":"*errx.ErrorWrapper","fields":{"a":2,"b":"x"}}]`, string(data))
+
+	chain, e = UnmarshalChain(data)
+	require.NoError(t, e)
+	remote := chain.Err()
+	require.Equal(t, []Field{{"a", float64(2)}, {"b", "x"}}, remote.(FieldCarrier).Fields())
+	require.Equal(t, map[string]interface{}{"a": float64(2), "b": "x"}, Fields(remote))
+}
@@ -0,0 +1,143 @@
+package errx
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// formatStackful 以 ErrorWrapper.Format() 的规则输出给定的 StackfulError 。
+func formatStackful(f fmt.State, verb rune, e StackfulError) {
+	var out string
+
+	switch verb {
+	case 's':
+		out = e.ErrorWithoutStack()
+	case 'q':
+		out = strconv.Quote(e.ErrorWithoutStack())
+	case 'v':
+		switch {
+		case f.Flag('#'):
+			out = goSyntax(e)
+		case f.Flag('+'):
+			out = Describe(e)
+		default:
+			out = e.Error()
+		}
+	case 'd', 'x', 'X', 'o', 'b':
+		if biz, ok := e.(BizError); ok {
+			// 保留宽度、补零等设置，如 %04d 。
+			out = fmt.Sprintf(formatDirective(f, verb), biz.Code())
+			break
+		}
+		out = "BADFORMAT:" + e.ErrorWithoutStack()
+	default:
+		// 其他不支持的格式，输出： BADFORMAT:Message()
+		out = "BADFORMAT:" + e.ErrorWithoutStack()
+	}
+
+	io.WriteString(f, out)
+}
+
+// formatDirective 还原 fmt.State 对应的格式，如 %-4d 。
+func formatDirective(f fmt.State, verb rune) string {
+	var b strings.Builder
+	b.WriteRune('%')
+	for _, flag := range "+-# 0" {
+		if f.Flag(int(flag)) {
+			b.WriteRune(flag)
+		}
+	}
+	if w, ok := f.Width(); ok {
+		b.WriteString(strconv.Itoa(w))
+	}
+	if p, ok := f.Precision(); ok {
+		b.WriteRune('.')
+		b.WriteString(strconv.Itoa(p))
+	}
+	b.WriteRune(verb)
+	return b.String()
+}
+
+// goSyntax 以 Go 语法风格输出错误的结构，用于 %#v ，如：
+//
+//	&errx.bizErr{Code:1, Message:"msg", Fields:[]errx.Field{...}, Frames:5, Cause:&errors.errorString{s:"gg"}}
+//
+// 调用栈仅输出 Frame 的数量。内部错误若也是 StackfulError ，则递归使用此格式，其他错误以 %#v 输出。
+// 与 Describe() 一样，错误链中的环和超出 DefaultMaxDepth 限制的部分以标记代替。
+func goSyntax(e StackfulError) string {
+	var b strings.Builder
+	visited := make(errorSet)
+	visited.add(e)
+	writeGoSyntax(&b, e, visited, 0)
+	return b.String()
+}
+
+// writeGoSyntax 输出 goSyntax() 的格式。 visited 是当前路径上的错误，包括 e 本身， depth 是 e 所在的层数。
+func writeGoSyntax(b *strings.Builder, e StackfulError, visited errorSet, depth int) {
+	// %T 得到的是 *errx.bizErr ，改为取地址的写法 &errx.bizErr{...} 。
+	typ := fmt.Sprintf("%T", e)
+	if strings.HasPrefix(typ, "*") {
+		typ = "&" + typ[1:]
+	}
+	b.WriteString(typ)
+	b.WriteRune('{')
+
+	if biz, ok := e.(BizError); ok {
+		fmt.Fprintf(b, "Code:%d, Message:%q", biz.Code(), biz.Message())
+	} else {
+		fmt.Fprintf(b, "Message:%q", e.ErrorWithoutStack())
+	}
+
+	if fc, ok := e.(FieldCarrier); ok {
+		if fields := fc.Fields(); len(fields) > 0 {
+			fmt.Fprintf(b, ", Fields:%#v", fields)
+		}
+	}
+
+	if st, ok := e.(StackTracer); ok {
+		if frames := st.Frames(); len(frames) > 0 {
+			fmt.Fprintf(b, ", Frames:%d", len(frames))
+		}
+	}
+
+	if multi, ok := e.(interface{ Unwrap() []error }); ok {
+		if errs := multi.Unwrap(); len(errs) > 0 {
+			b.WriteString(", Errors:[]error{")
+			for i, inner := range errs {
+				if i > 0 {
+					b.WriteString(", ")
+				}
+				writeInnerGoSyntax(b, inner, visited, depth+1)
+			}
+			b.WriteRune('}')
+		}
+	} else if cause := e.Cause(); cause != nil {
+		b.WriteString(", Cause:")
+		writeInnerGoSyntax(b, cause, visited, depth+1)
+	}
+
+	b.WriteRune('}')
+}
+
+// writeInnerGoSyntax 输出内部错误的 goSyntax() 格式，参数的含义同 writeGoSyntax() 。
+func writeInnerGoSyntax(b *strings.Builder, err error, visited errorSet, depth int) {
+	se, ok := err.(StackfulError)
+	if !ok {
+		fmt.Fprintf(b, "%#v", err)
+		return
+	}
+
+	if chainDepthExceeded(depth, DefaultMaxDepth) {
+		b.WriteString(truncatedMarker(err, visited))
+		return
+	}
+
+	if !visited.add(err) {
+		b.WriteString(cycleMarker)
+		return
+	}
+	writeGoSyntax(b, se, visited, depth)
+	visited.remove(err)
+}
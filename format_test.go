@@ -0,0 +1,63 @@
+package errx
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestBizError_Format(t *testing.T) {
+	err := NewBizError(26, "biz", errors.New("gg"))
+
+	require.Equal(t, "(26) biz", fmt.Sprintf("%s", err))
+	require.Equal(t, `"(26) biz"`, fmt.Sprintf("%q", err))
+	require.Equal(t, "(26) biz", fmt.Sprintf("%v", err))
+	require.Equal(t, Describe(err), fmt.Sprintf("%+v", err))
+	require.Regexp(t, `^\(26\) biz\n--- \[.+format_test\.go:\d+\] go-errx\.TestBizError_Format\n(.+\n)*=== gg\n$`, fmt.Sprintf("%+v", err))
+
+	require.Equal(t, "26", fmt.Sprintf("%d", err))
+	require.Equal(t, "0026", fmt.Sprintf("%04d", err))
+	require.Equal(t, "1a", fmt.Sprintf("%x", err))
+	require.Equal(t, "0X1A", fmt.Sprintf("%#X", err))
+	require.Equal(t, "BADFORMAT:(26) biz", fmt.Sprintf("%f", err))
+
+	require.Regexp(t, `^&errx\.bizErr\{Code:26, Message:"biz", Frames:\d+, Cause:&errors\.errorString\{s:"gg"\}\}$`, fmt.Sprintf("%#v", err))
+}
+
+func TestFormat_GoSyntax(t *testing.T) {
+	t.Run("wrapper", func(t *testing.T) {
+		err := WrapWithoutStack("p1", With(NewBizErrorWithoutStack(1, "biz", nil), "k", "v"))
+		require.Equal(t,
+			`&errx.ErrorWrapper{Message:"p1: (1) biz", Cause:&errx.bizErr{Code:1, Message:"biz", Fields:[]errx.Field{errx.Field{Key:"k", Value:"v"}}}}`,
+			fmt.Sprintf("%#v", err))
+	})
+
+	t.Run("multi", func(t *testing.T) {
+		m := (&Multi{msg: "m"}).Append(errors.New("a"), NewBizErrorWithoutStack(2, "b", nil))
+		require.Regexp(t,
+			`^&errx\.Multi\{Message:"m: a; \(2\) b", Errors:\[\]error\{&errors\.errorString\{s:"a"\}, &errx\.bizErr\{Code:2, Message:"b"\}\}\}$`,
+			fmt.Sprintf("%#v", m))
+	})
+
+	t.Run("panic", func(t *testing.T) {
+		err := Run(func() { panic(1) })
+		require.Regexp(t, `^&errx\.PanicError\{Message:"1", Frames:\d+, Cause:&errors\.errorString\{s:"1"\}\}$`, fmt.Sprintf("%#v", err))
+	})
+}
+
+func TestFormat_Remote(t *testing.T) {
+	err := NewErrorChain(WrapWithoutStack("p", NewBizErrorWithoutStack(3, "biz", nil))).Err()
+	require.Equal(t, "p: (3) biz", fmt.Sprintf("%s", err))
+	require.Equal(t, "BADFORMAT:p: (3) biz", fmt.Sprintf("%d", err))
+
+	biz := errors.Unwrap(err)
+	require.Equal(t, "(3) biz", fmt.Sprintf("%v", biz))
+	require.Equal(t, "3", fmt.Sprintf("%d", biz))
+	require.Equal(t, `&errx.RemoteBizError{Code:3, Message:"biz"}`, fmt.Sprintf("%#v", biz))
+}
+
+func TestErrorWrapper_FormatGoSyntax(t *testing.T) {
+	require.Equal(t, `&errx.ErrorWrapper{Message:"p"}`, fmt.Sprintf("%#v", WrapWithoutStack("p", nil)))
+}
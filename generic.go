@@ -0,0 +1,48 @@
+//go:build go1.21
+
+package errx
+
+// 此文件提供使用泛型的方法。泛型自 Go 1.18 起可用，但 go.mod 声明的版本低于 1.18 时，
+// Go 1.21 之前的编译器不允许在此模块中使用泛型，故此文件仅在 Go 1.21 及以上编译。
+
+// RunT 执行带有一个返回值和一个 error 返回值的函数。
+// 若函数成功执行，返回函数的返回值；若函数 panic ，则通过 [PreserveRecover] 捕获，返回 T 的零值和对应的 *PanicError 。
+func RunT[T any](f func() (T, error)) (res T, err error) {
+	pkg := capturePackage(3) // 包级别的策略按调用当前函数的函数所在的包判断。
+	defer func() {
+		if pe := recoverPanic("", recover(), pkg, 3); pe != nil {
+			var zero T
+			res, err = zero, pe
+		}
+	}()
+
+	return f()
+}
+
+// Must 若 err 不为 nil ，以 err 触发 panic ；否则返回 v 。通常与 Try() 配合使用，省去逐个判断 error ：
+//
+//	conf, err := errx.Try(func() Config {
+//		data := errx.Must(os.ReadFile(path))
+//		return errx.Must(parseConfig(data))
+//	})
+func Must[T any](v T, err error) T {
+	if err != nil {
+		panic(err)
+	}
+	return v
+}
+
+// Try 执行给定的函数。若函数成功执行，返回函数的返回值；若函数 panic （包括 Must() 触发的），
+// 则通过 [PreserveRecover] 捕获，返回 T 的零值和对应的 *PanicError 。
+// 对于 Must() 触发的 panic ， *PanicError 的 Cause() 即是给 Must() 的 error 。
+func Try[T any](f func() T) (res T, err error) {
+	pkg := capturePackage(3) // 包级别的策略按调用当前函数的函数所在的包判断。
+	defer func() {
+		if pe := recoverPanic("", recover(), pkg, 3); pe != nil {
+			var zero T
+			res, err = zero, pe
+		}
+	}()
+
+	return f(), nil
+}
@@ -0,0 +1,65 @@
+//go:build go1.21
+
+package errx
+
+import (
+	"errors"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunT(t *testing.T) {
+	t.Run("ok", func(t *testing.T) {
+		v, err := RunT(func() (int, error) { return 1, nil })
+		require.NoError(t, err)
+		require.Equal(t, 1, v)
+	})
+
+	t.Run("err", func(t *testing.T) {
+		cause := errors.New("gg")
+		v, err := RunT(func() (int, error) { return 2, cause })
+		require.Equal(t, cause, err)
+		require.Equal(t, 2, v)
+	})
+
+	t.Run("panic", func(t *testing.T) {
+		v, err := RunT(func() (string, error) { panic("gg") })
+		require.Equal(t, "", v)
+		require.True(t, IsPanic(err))
+		require.Equal(t, "gg", err.(*PanicError).Value())
+		require.Equal(t, "github.com/cmstar/go-errx.TestRunT.func3.1", err.(*PanicError).Frames()[0].Function)
+	})
+}
+
+func TestMust(t *testing.T) {
+	require.Equal(t, 1, Must(strconv.Atoi("1")))
+
+	require.Panics(t, func() {
+		Must(strconv.Atoi("x"))
+	})
+}
+
+func TestTry(t *testing.T) {
+	t.Run("ok", func(t *testing.T) {
+		v, err := Try(func() int { return Must(strconv.Atoi("12")) })
+		require.NoError(t, err)
+		require.Equal(t, 12, v)
+	})
+
+	t.Run("must", func(t *testing.T) {
+		v, err := Try(func() int { return Must(strconv.Atoi("x")) })
+		require.Equal(t, 0, v)
+
+		var numErr *strconv.NumError
+		require.True(t, errors.As(err, &numErr))
+		require.Equal(t, "github.com/cmstar/go-errx.Must[...]", err.(*PanicError).Frames()[0].Function)
+	})
+
+	t.Run("panic", func(t *testing.T) {
+		v, err := Try(func() []int { panic(1) })
+		require.Nil(t, v)
+		require.Equal(t, 1, err.(*PanicError).Value())
+	})
+}
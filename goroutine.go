@@ -0,0 +1,122 @@
+package errx
+
+import (
+	"log"
+	"sync"
+	"sync/atomic"
+)
+
+// goErrorMessage 是 Go() 等返回的错误的描述，该层错误记录了启动 goroutine 处的调用栈。
+const goErrorMessage = "goroutine"
+
+// Go 启动一个 goroutine 执行给定的函数，并通过 PreserveRecover() 捕获其 panic ，避免整个进程退出。
+// 捕获的错误交给 SetGoErrorHandler() 设置的函数处理。
+//
+// 错误链的最外层记录了调用 Go() 处的调用栈，其内层是 PreserveRecover() 的结果，记录了 panic 处的调用栈。
+func Go(f func()) {
+	// goroutine 中的 panic 也按调用 Go() 的函数所在的包判断调用栈记录策略。
+	pkg := capturePackage(3)
+	spawn := captureStack(CaptureInfo{Kind: CaptureGo, Package: pkg}, 3) // 调用栈不包括当前函数。
+	go func() {
+		err := run(pkg, f)
+		if err != nil {
+			handleGoError(goError(spawn, err))
+		}
+	}()
+}
+
+// GoE 与 Go() 相同，但函数返回的错误也交给 SetGoErrorHandler() 设置的函数处理。
+func GoE(f func() error) {
+	pkg := capturePackage(3)
+	spawn := captureStack(CaptureInfo{Kind: CaptureGo, Package: pkg}, 3) // 调用栈不包括当前函数。
+	go func() {
+		err := runE(pkg, f)
+		if err != nil {
+			handleGoError(goError(spawn, err))
+		}
+	}()
+}
+
+// SetGoErrorHandler 设置处理 Go() 和 GoE() 产生的错误的函数，它在出错的 goroutine 中被调用。
+// 默认通过标准库的 log 包输出 Describe() 的结果。给定 nil 时恢复默认设置。
+func SetGoErrorHandler(h func(err error)) {
+	if h == nil {
+		h = defaultGoErrorHandler
+	}
+	goErrorHandlerValue.Store(h)
+}
+
+var goErrorHandlerValue atomic.Value
+
+func defaultGoErrorHandler(err error) {
+	log.Print(Describe(err))
+}
+
+func handleGoError(err error) {
+	h, _ := goErrorHandlerValue.Load().(func(error))
+	if h == nil {
+		h = defaultGoErrorHandler
+	}
+	h(err)
+}
+
+// goError 以启动 goroutine 处的调用栈包装 goroutine 产生的错误。
+func goError(spawn ErrorStack, err error) error {
+	return &ErrorWrapper{
+		ErrorCause: ErrorCause{err},
+		ErrorStack: spawn,
+		msg:        goErrorMessage,
+	}
+}
+
+// Group 用于启动一组 goroutine 并等待它们结束，类似于 golang.org/x/sync/errgroup ，
+// 但会通过 PreserveRecover() 捕获各个 goroutine 的 panic ，并收集所有的错误，而不仅是第一个。
+//
+// 零值的 Group 即可使用。 Group 在使用后不应被复制。
+type Group struct {
+	wg   sync.WaitGroup
+	mu   sync.Mutex
+	errs []error
+}
+
+// Go 启动一个 goroutine 执行给定的函数。函数返回的错误或 panic 被捕获后，由 Wait() 返回。
+// 与 errx.Go() 一样，错误记录了调用 Go() 处和 panic 处的调用栈。
+func (g *Group) Go(f func() error) {
+	pkg := capturePackage(3)
+	spawn := captureStack(CaptureInfo{Kind: CaptureGo, Package: pkg}, 3) // 调用栈不包括当前函数。
+
+	g.wg.Add(1)
+	go func() {
+		defer g.wg.Done()
+
+		err := runE(pkg, f)
+		if err == nil {
+			return
+		}
+
+		g.mu.Lock()
+		g.errs = append(g.errs, goError(spawn, err))
+		g.mu.Unlock()
+	}()
+}
+
+// Wait 等待所有通过 Go() 启动的 goroutine 结束。若没有错误，返回 nil ；
+// 否则返回一个 *Multi ，包含所有的错误，按产生的先后顺序排列，并记录调用 Wait() 处的调用栈。
+func (g *Group) Wait() error {
+	g.wg.Wait()
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if len(g.errs) == 0 {
+		return nil
+	}
+
+	errs := make([]error, len(g.errs))
+	copy(errs, g.errs)
+	return &Multi{
+		ErrorStack: captureStack(CaptureInfo{Kind: CaptureMulti}, 3), // 调用栈不包括当前函数。
+		msg:        "goroutine group",
+		errs:       errs,
+	}
+}
@@ -0,0 +1,89 @@
+package errx
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestGo(t *testing.T) {
+	errs := make(chan error, 1)
+	SetGoErrorHandler(func(err error) { errs <- err })
+	defer SetGoErrorHandler(nil)
+
+	t.Run("panic", func(t *testing.T) {
+		Go(func() {
+			panic("gg")
+		})
+
+		err := <-errs
+		w := err.(*ErrorWrapper)
+		require.Equal(t, "goroutine: gg", w.ErrorWithoutStack())
+		require.Equal(t, "github.com/cmstar/go-errx.TestGo.func2", w.Frames()[0].Function)
+
+		inner := w.Cause().(*PanicError)
+		require.Equal(t, "gg", inner.Value())
+		require.Equal(t, "github.com/cmstar/go-errx.TestGo.func2.1", inner.Frames()[0].Function)
+	})
+
+	t.Run("error", func(t *testing.T) {
+		cause := errors.New("gg")
+		GoE(func() error {
+			return cause
+		})
+
+		err := <-errs
+		require.Equal(t, "github.com/cmstar/go-errx.TestGo.func3", err.(*ErrorWrapper).Frames()[0].Function)
+		require.Equal(t, cause, errors.Unwrap(err))
+	})
+
+	t.Run("ok", func(t *testing.T) {
+		done := make(chan struct{})
+		GoE(func() error {
+			defer close(done)
+			return nil
+		})
+		<-done
+
+		select {
+		case err := <-errs:
+			t.Fatalf("unexpected error: %v", err)
+		default:
+		}
+	})
+}
+
+func TestGroup(t *testing.T) {
+	t.Run("empty", func(t *testing.T) {
+		var g Group
+		require.NoError(t, g.Wait())
+	})
+
+	t.Run("ok", func(t *testing.T) {
+		var g Group
+		for i := 0; i < 3; i++ {
+			g.Go(func() error { return nil })
+		}
+		require.NoError(t, g.Wait())
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		var g Group
+		g.Go(func() error { return NewBizError(1, "biz", nil) })
+		g.Go(func() error { panic("gg") })
+		g.Go(func() error { return nil })
+
+		err := g.Wait()
+		var m *Multi
+		require.True(t, errors.As(err, &m))
+		require.Equal(t, 2, m.Len())
+		require.True(t, HasCode(m.Errors()[0], 1) || HasCode(m.Errors()[1], 1))
+
+		for _, e := range m.Errors() {
+			require.Equal(t, "github.com/cmstar/go-errx.TestGroup.func3", e.(*ErrorWrapper).Frames()[0].Function)
+		}
+		require.True(t, strings.HasPrefix(m.ErrorWithoutStack(), "goroutine group: goroutine: "))
+	})
+}
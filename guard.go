@@ -0,0 +1,154 @@
+package errx
+
+import (
+	"errors"
+	"reflect"
+	"strconv"
+)
+
+// DefaultMaxDepth 是 NewPrinter() 得到的 Printer.MaxDepth ，即 Describe() 最多输出的错误链的层数。
+// ErrorWithoutStack() 、 %#v 格式和 ErrorLogValue() 等逐层输出错误链的方法同样最多输出这些层，
+// 超出的部分以“<truncated N more>”代替，以免异常的错误链（如反复重试时逐次封装的错误）输出过多的内容。
+//
+// 无论是否限制层数，错误链中的环（某一层的内部错误是其外层的错误）都会被检测，重复的错误以“<cycle detected>”代替。
+const DefaultMaxDepth = 100
+
+const (
+	// cycleMarker 在错误链中有环时，代替重复的错误输出。
+	cycleMarker = "<cycle detected>"
+
+	// truncatedPrefix 和 truncatedSuffix 组成“<truncated N more>”，在错误链超过层数的限制时，
+	// 代替超出的部分输出， N 是超出的层数。见 DefaultMaxDepth 和 Printer.MaxDepth 。
+	truncatedPrefix = "<truncated "
+	truncatedSuffix = " more>"
+)
+
+// chainDepthExceeded 判断给定的层数是否超出了最多 max 层的限制。 max 小于等于 0 表示不限制。
+func chainDepthExceeded(depth, max int) bool {
+	return max > 0 && depth >= max
+}
+
+// truncatedMarker 返回 err 及其内部错误被截断时输出的标记，即“<truncated N more>”。
+// N 是 err 及通过 errors.Unwrap() 得到的内部错误的数量，不计入 visited 中已有的错误。
+func truncatedMarker(err error, visited errorSet) string {
+	n := 0
+	seen := make(errorSet)
+	for ; err != nil; err = errors.Unwrap(err) {
+		if visited.has(err) || !seen.add(err) {
+			break
+		}
+		n++
+	}
+	return truncatedPrefix + strconv.Itoa(n) + truncatedSuffix
+}
+
+// errorSet 记录已访问的错误，用于检测错误链中的环。
+type errorSet map[interface{}]struct{}
+
+// errorKey 是指针类型的错误在 errorSet 中的键，以类型和地址表示错误的标识。
+type errorKey struct {
+	typ reflect.Type
+	ptr uintptr
+}
+
+// setKey 返回错误在 errorSet 中的键。
+// 指针等引用类型的错误按地址判断；结构体和数组无法安全地比较，第二个返回值为 false ；其他类型按值判断。
+func setKey(err error) (interface{}, bool) {
+	v := reflect.ValueOf(err)
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Map, reflect.Slice, reflect.Func, reflect.Chan, reflect.UnsafePointer:
+		return errorKey{v.Type(), v.Pointer()}, true
+	case reflect.Struct, reflect.Array:
+		// 其字段中可能有不可比较的值，作为 map 的键会 panic 。值类型的错误通常也不会形成环。
+		return nil, false
+	default:
+		return err, true
+	}
+}
+
+// add 将错误加入集合，若错误已存在，返回 false 。无法判断的错误（见 setKey() ）总是返回 true 。
+func (s errorSet) add(err error) bool {
+	key, ok := setKey(err)
+	if !ok {
+		return true
+	}
+
+	if _, ok := s[key]; ok {
+		return false
+	}
+	s[key] = struct{}{}
+	return true
+}
+
+// has 判断错误是否在集合中。
+func (s errorSet) has(err error) bool {
+	key, ok := setKey(err)
+	if !ok {
+		return false
+	}
+
+	_, ok = s[key]
+	return ok
+}
+
+// remove 将错误从集合中移除。
+func (s errorSet) remove(err error) {
+	if key, ok := setKey(err); ok {
+		delete(s, key)
+	}
+}
+
+// messageWithoutStack 返回错误链的 ErrorWithoutStack() ，用于 ErrorWrapper 、 Multi 等。
+// 它逐层处理 errx 自身的错误，检测其中的环并限制层数，见 DefaultMaxDepth 。
+func messageWithoutStack(err error) string {
+	var b []byte
+	b = appendMessage(b, err, make(errorSet), 0)
+	return string(b)
+}
+
+// appendMessage 将 err 及其内部错误的描述追加到 b 。 visited 是外层的错误， depth 是 err 所在的层数。
+func appendMessage(b []byte, err error, visited errorSet, depth int) []byte {
+	// 仅记录当前路径上的错误，离开时移除，以免同一个错误出现在多个分支时被误判为环。
+	var added []error
+	defer func() {
+		for _, e := range added {
+			visited.remove(e)
+		}
+	}()
+
+	for ; err != nil; depth++ {
+		if chainDepthExceeded(depth, DefaultMaxDepth) {
+			return append(b, truncatedMarker(err, visited)...)
+		}
+
+		if !visited.add(err) {
+			return append(b, cycleMarker...)
+		}
+		added = append(added, err)
+
+		var w *ErrorWrapper
+		switch e := err.(type) {
+		case *ErrorWrapper:
+			w = e
+		case *PanicError:
+			w = &e.ErrorWrapper
+		case *Multi:
+			return e.appendMessage(b, visited, depth)
+		case StackfulError:
+			return append(b, e.ErrorWithoutStack()...)
+		default:
+			return append(b, e.Error()...)
+		}
+
+		if w.Err == nil || w.verbatim {
+			return append(b, w.msg...)
+		}
+
+		if w.msg != "" {
+			b = append(b, w.msg...)
+			b = append(b, ": "...)
+		}
+		err = w.Err
+	}
+	return b
+}
@@ -0,0 +1,148 @@
+package errx
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCycle(t *testing.T) {
+	t.Run("self", func(t *testing.T) {
+		w := WrapWithoutStack("a", nil).(*ErrorWrapper)
+		w.Err = w
+		require.Equal(t, "a: <cycle detected>", w.ErrorWithoutStack())
+		require.Equal(t, "a: <cycle detected>\n=== <cycle detected>\n", Describe(w))
+	})
+
+	t.Run("two", func(t *testing.T) {
+		w1 := WrapWithoutStack("a", nil).(*ErrorWrapper)
+		w2 := Wrap("b", w1)
+		w1.Err = w2
+		require.Equal(t, "a: b: <cycle detected>", w1.ErrorWithoutStack())
+		require.Regexp(t, `^a: b: <cycle detected>\n=== b: a: <cycle detected>\n--- .+\n(.+\n)*=== <cycle detected>\n$`, Describe(w1))
+	})
+
+	t.Run("foreign", func(t *testing.T) {
+		c := &cycleError{name: "c"}
+		w := WrapWithoutStack("a", c)
+		c.next = w
+		require.Equal(t, "a: c", w.ErrorWithoutStack())
+		require.Equal(t, "a: c\n=== c\n=== <cycle detected>\n", Describe(w))
+	})
+
+	t.Run("multi", func(t *testing.T) {
+		m := &Multi{msg: "m"}
+		m.Append(m, errors.New("x"))
+		require.Equal(t, "m: <cycle detected>; x", m.ErrorWithoutStack())
+		require.Equal(t, "m: <cycle detected>; x\n=== [1/2] <cycle detected>\n=== [2/2] x\n", Describe(m))
+	})
+
+	t.Run("go-syntax", func(t *testing.T) {
+		w := WrapWithoutStack("a", nil).(*ErrorWrapper)
+		w.Err = w
+		require.Equal(t, `&errx.ErrorWrapper{Message:"a: <cycle detected>", Cause:<cycle detected>}`, fmt.Sprintf("%#v", w))
+
+		m := &Multi{msg: "m"}
+		m.Append(m, errors.New("x"))
+		require.Equal(t, `&errx.Multi{Message:"m: <cycle detected>; x", Errors:[]error{<cycle detected>, &errors.errorString{s:"x"}}}`, fmt.Sprintf("%#v", m))
+	})
+
+	t.Run("capture-policy", func(t *testing.T) {
+		defer resetCapturePolicy()
+		SetCapturePolicy(CaptureIfCauseHasNoStack())
+
+		w := WrapWithoutStack("a", nil).(*ErrorWrapper)
+		w.Err = w
+		require.NotEqual(t, "", Wrap("c", w).Stack())
+
+		m := &Multi{msg: "m"}
+		m.Append(m, Wrap("x", nil))
+		require.Equal(t, "", Wrap("c", m).Stack())
+	})
+
+	t.Run("shared", func(t *testing.T) {
+		// 同一个错误出现在多个分支中，不是环。
+		e := WrapWithoutStack("e", nil)
+		m := (&Multi{msg: "m"}).Append(e, e)
+		require.Equal(t, "m: e; e", m.ErrorWithoutStack())
+		require.Equal(t, "m: e; e\n=== [1/2] e\n=== [2/2] e\n", Describe(m))
+	})
+}
+
+func TestMaxDepth(t *testing.T) {
+	// l0 -> l1 -> l2 -> l3 -> l4 -> root
+	var err error = errors.New("root")
+	for i := 4; i >= 0; i-- {
+		err = WrapWithoutStack(fmt.Sprint("l", i), err)
+	}
+
+	p := NewPrinter()
+	p.MaxDepth = 3
+	require.Equal(t, ""+
+		"l0: l1: l2: l3: l4: root\n"+
+		"=== l1: l2: l3: l4: root\n"+
+		"=== l2: l3: l4: root\n"+
+		"=== <truncated 3 more>\n",
+		p.Describe(err))
+
+	t.Run("multi", func(t *testing.T) {
+		p := NewPrinter()
+		p.MaxDepth = 2
+		m := (&Multi{msg: "m"}).Append(WrapWithoutStack("a", errors.New("b")))
+		require.Equal(t, "m: a: b\n=== [1/1] a: b\n    === <truncated 1 more>\n", p.Describe(m))
+	})
+
+	t.Run("unlimited", func(t *testing.T) {
+		var deep error = errors.New("root")
+		for i := 0; i < DefaultMaxDepth*2; i++ {
+			deep = WrapWithoutStack("w", deep)
+		}
+
+		p := NewPrinter()
+		p.MaxDepth = 0
+		res := p.Describe(deep)
+		require.Equal(t, DefaultMaxDepth*2+1, strings.Count(res, "\n"))
+		require.True(t, strings.HasSuffix(res, "\n=== root\n"))
+	})
+
+	t.Run("default", func(t *testing.T) {
+		var deep error = errors.New("root")
+		for i := 0; i < DefaultMaxDepth+10; i++ {
+			deep = WrapWithoutStack("w", deep)
+		}
+
+		msg := deep.(StackfulError).ErrorWithoutStack()
+		require.Equal(t, DefaultMaxDepth, strings.Count(msg, "w: "))
+		require.True(t, strings.HasSuffix(msg, "w: <truncated 11 more>"))
+		require.True(t, strings.HasSuffix(Describe(deep), "\n=== <truncated 11 more>\n"))
+		require.Contains(t, fmt.Sprintf("%#v", deep), "Cause:<truncated 11 more>}")
+	})
+}
+
+func TestErrorSet(t *testing.T) {
+	s := make(errorSet)
+
+	e := errors.New("e")
+	require.True(t, s.add(e))
+	require.False(t, s.add(e))
+	require.True(t, s.has(e))
+	s.remove(e)
+	require.False(t, s.has(e))
+
+	// 结构体不可安全地比较，总是视为未访问。
+	v := valueError{data: []int{1}}
+	require.True(t, s.add(v))
+	require.True(t, s.add(v))
+	require.False(t, s.has(v))
+
+	// 其他值类型按值判断。
+	require.True(t, s.add(stringError("a")))
+	require.False(t, s.add(stringError("a")))
+}
+
+type stringError string
+
+func (e stringError) Error() string { return string(e) }
@@ -0,0 +1,123 @@
+package httpx
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+
+	"github.com/cmstar/go-errx"
+)
+
+// ProblemContentType 是 RFC 7807 定义的 Content-Type 。
+const ProblemContentType = "application/problem+json"
+
+// Problem 是 RFC 7807 定义的 problem details 对象，用于向客户端描述错误。
+type Problem struct {
+	// Type 是标识错误类型的 URI 。为空时不输出，按 RFC 7807 等同于 about:blank 。
+	Type string `json:"type,omitempty"`
+
+	// Title 是错误类型的简短描述，使用状态码的标准描述，如 Bad Request 。
+	Title string `json:"title"`
+
+	// Status 是 HTTP 状态码。
+	Status int `json:"status"`
+
+	// Detail 是错误的描述。对于 BizError 是 BizError.Message() ，其他错误不输出，以免泄露内部细节。
+	Detail string `json:"detail,omitempty"`
+
+	// Instance 是标识发生错误的请求的 URI ，见 Responder.Instance 。
+	Instance string `json:"instance,omitempty"`
+
+	// Code 是 BizError 的错误码。其他错误不输出。
+	Code *int `json:"code,omitempty"`
+
+	// TraceID 是请求的追踪 ID ，用于关联日志，见 Responder.TraceID 。
+	TraceID string `json:"traceId,omitempty"`
+}
+
+// Responder 将错误以 Problem 的格式输出到 HTTP 响应中。
+//
+// 输出的内容仅包含 BizError 的错误码和描述，内部错误和调用栈不会输出给客户端，
+// 而是通过 errx.Describe() 得到完整的描述，交给 Log 记录。
+//
+// 通常应通过 NewResponder() 创建，再修改需要调整的字段。字段在使用期间不应被修改。
+type Responder struct {
+	// Mapper 用于得到错误对应的 HTTP 状态码。
+	Mapper *StatusMapper
+
+	// Instance 返回 Problem.Instance ，为 nil 时不输出。
+	Instance func(r *http.Request) string
+
+	// TraceID 返回 Problem.TraceID ，为 nil 时不输出。
+	TraceID func(r *http.Request) string
+
+	// Log 用于记录错误的完整信息， description 是 errx.Describe() 的结果。为 nil 时不记录。
+	// 默认通过标准库的 log 包输出。
+	Log func(r *http.Request, status int, description string)
+}
+
+// NewResponder 创建一个 Responder ，使用 NewStatusMapper() 的设置，并通过标准库的 log 包记录错误。
+func NewResponder() *Responder {
+	return &Responder{
+		Mapper: NewStatusMapper(),
+		Log:    logError,
+	}
+}
+
+// DefaultResponder 是 WriteError() 使用的 Responder 。
+var DefaultResponder = NewResponder()
+
+// WriteError 使用 DefaultResponder 输出错误，见 Responder.WriteError() 。
+func WriteError(w http.ResponseWriter, r *http.Request, err error) {
+	DefaultResponder.WriteError(w, r, err)
+}
+
+// Problem 返回给定错误对应的 Problem 。 r 可以为 nil ，此时不输出 Instance 和 TraceID 。
+func (rs *Responder) Problem(r *http.Request, err error) Problem {
+	p := Problem{
+		Status: rs.Mapper.Status(err),
+	}
+	p.Title = http.StatusText(p.Status)
+
+	if biz, ok := findBizError(err); ok {
+		code := biz.Code()
+		p.Code = &code
+		p.Detail = biz.Message()
+	}
+
+	if r != nil {
+		if rs.Instance != nil {
+			p.Instance = rs.Instance(r)
+		}
+		if rs.TraceID != nil {
+			p.TraceID = rs.TraceID(r)
+		}
+	}
+
+	return p
+}
+
+// WriteError 将给定的错误以 Problem 的格式写入响应，并通过 Log 记录错误的完整信息。若 err 为 nil ，不做任何处理。
+func (rs *Responder) WriteError(w http.ResponseWriter, r *http.Request, err error) {
+	if err == nil {
+		return
+	}
+
+	p := rs.Problem(r, err)
+	if rs.Log != nil {
+		rs.Log(r, p.Status, errx.Describe(err))
+	}
+
+	w.Header().Set("Content-Type", ProblemContentType)
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(p.Status)
+	json.NewEncoder(w).Encode(p) // 状态码已输出，写入失败也无法再处理。
+}
+
+func logError(r *http.Request, status int, description string) {
+	if r == nil {
+		log.Printf("%d %s", status, description)
+		return
+	}
+	log.Printf("%s %s %d %s", r.Method, r.URL.RequestURI(), status, description)
+}
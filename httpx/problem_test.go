@@ -0,0 +1,79 @@
+package httpx
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/cmstar/go-errx"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResponder_WriteError(t *testing.T) {
+	var logged []string
+	rs := NewResponder()
+	rs.Instance = func(r *http.Request) string { return r.URL.Path }
+	rs.TraceID = func(r *http.Request) string { return r.Header.Get("X-Trace-Id") }
+	rs.Log = func(r *http.Request, status int, description string) {
+		logged = append(logged, description)
+	}
+
+	req := httptest.NewRequest("GET", "/users/1?x=1", nil)
+	req.Header.Set("X-Trace-Id", "t1")
+
+	t.Run("biz", func(t *testing.T) {
+		logged = nil
+		err := errx.Wrap("load", errx.NewBizError(1001, "user not found", errors.New("no rows")))
+
+		w := httptest.NewRecorder()
+		rs.WriteError(w, req, err)
+
+		require.Equal(t, http.StatusBadRequest, w.Code)
+		require.Equal(t, ProblemContentType, w.Header().Get("Content-Type"))
+		require.JSONEq(t, `{
+			"title": "Bad Request",
+			"status": 400,
+			"detail": "user not found",
+			"instance": "/users/1",
+			"code": 1001,
+			"traceId": "t1"
+		}`, w.Body.String())
+
+		require.Len(t, logged, 1)
+		require.Equal(t, errx.Describe(err), logged[0])
+	})
+
+	t.Run("internal", func(t *testing.T) {
+		logged = nil
+		err := errx.Wrap("query failed", errors.New("secret"))
+
+		w := httptest.NewRecorder()
+		rs.WriteError(w, req, err)
+
+		require.Equal(t, http.StatusInternalServerError, w.Code)
+		require.JSONEq(t, `{
+			"title": "Internal Server Error",
+			"status": 500,
+			"instance": "/users/1",
+			"traceId": "t1"
+		}`, w.Body.String())
+
+		require.Len(t, logged, 1)
+		require.Contains(t, logged[0], "secret")
+	})
+
+	t.Run("nil", func(t *testing.T) {
+		logged = nil
+		w := httptest.NewRecorder()
+		rs.WriteError(w, req, nil)
+		require.Equal(t, 0, w.Body.Len())
+		require.Nil(t, logged)
+	})
+}
+
+func TestResponder_Problem(t *testing.T) {
+	p := NewResponder().Problem(nil, errx.NewBizError(0, "m", nil))
+	code := 0
+	require.Equal(t, Problem{Title: "Bad Request", Status: 400, Detail: "m", Code: &code}, p)
+}
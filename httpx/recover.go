@@ -0,0 +1,125 @@
+package httpx
+
+import (
+	"bufio"
+	"errors"
+	"net"
+	"net/http"
+
+	"github.com/cmstar/go-errx"
+)
+
+// Recoverer 是捕获 panic 的 http.Handler 中间件。
+//
+// handler panic 时，通过 errx.PreserveRecover() 将 panic 的数据封装为携带调用栈的 StackfulError ，
+// 交给 Report ，再通过 Responder 输出响应：若 panic 的是 BizError （或错误链上有 BizError ），状态码按 StatusMapper 映射，
+// 否则为 500 。与 Responder.WriteError() 一样，内部错误和调用栈不会输出给客户端。
+//
+// 若 handler 在 panic 之前已开始输出响应（调用了 WriteHeader() 、 Write() 等），无法再写入错误响应，
+// 此时仍会调用 Report 和 Responder.Log ，但不再输出响应。
+//
+// panic 的值是 http.ErrAbortHandler 时，不做处理，继续 panic ，由 net/http 中止响应。
+//
+// 通常应通过 NewRecoverer() 创建，再修改需要调整的字段。字段在使用期间不应被修改。
+type Recoverer struct {
+	// Responder 用于输出错误响应，其 Log 同样会记录 panic 产生的错误。
+	Responder *Responder
+
+	// Report 接收 panic 产生的错误，可用于报警、统计等。为 nil 时不调用。
+	Report func(r *http.Request, err errx.StackfulError)
+}
+
+// NewRecoverer 创建一个 Recoverer ，使用 DefaultResponder 输出响应。
+func NewRecoverer() *Recoverer {
+	return &Recoverer{
+		Responder: DefaultResponder,
+	}
+}
+
+// Recover 使用 NewRecoverer() 的设置包装给定的 http.Handler ，见 Recoverer 。
+func Recover(next http.Handler) http.Handler {
+	return NewRecoverer().Wrap(next)
+}
+
+// Wrap 包装给定的 http.Handler ，捕获其 panic 。
+func (rc *Recoverer) Wrap(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		tw := &trackingWriter{ResponseWriter: w}
+		defer func() {
+			recovered := recover()
+			if recovered == nil {
+				return
+			}
+
+			if recovered == http.ErrAbortHandler {
+				panic(recovered)
+			}
+
+			err := errx.PreserveRecover("panic serving "+r.Method+" "+r.URL.Path, recovered)
+			if rc.Report != nil {
+				rc.Report(r, err)
+			}
+
+			if !tw.started {
+				rc.Responder.WriteError(w, r, err)
+			} else if rc.Responder.Log != nil {
+				rc.Responder.Log(r, tw.status, errx.Describe(err))
+			}
+		}()
+
+		next.ServeHTTP(tw, r)
+	})
+}
+
+// trackingWriter 包装 http.ResponseWriter ，记录响应是否已开始输出。
+type trackingWriter struct {
+	http.ResponseWriter
+	started bool
+	status  int
+}
+
+func (w *trackingWriter) WriteHeader(status int) {
+	if !w.started {
+		w.started = true
+		w.status = status
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *trackingWriter) Write(b []byte) (int, error) {
+	if !w.started {
+		w.started = true
+		w.status = http.StatusOK
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+// Flush 实现 http.Flusher 。若被包装的 http.ResponseWriter 不支持，不做任何处理。
+func (w *trackingWriter) Flush() {
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		if !w.started {
+			w.started = true
+			w.status = http.StatusOK
+		}
+		f.Flush()
+	}
+}
+
+// Hijack 实现 http.Hijacker 。连接被接管后，同样不能再输出错误响应。
+func (w *trackingWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	h, ok := w.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, errors.New("httpx: the ResponseWriter does not implement http.Hijacker")
+	}
+
+	conn, rw, err := h.Hijack()
+	if err == nil {
+		w.started = true
+	}
+	return conn, rw, err
+}
+
+// Unwrap 返回被包装的 http.ResponseWriter ，供 http.ResponseController 使用。
+func (w *trackingWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
@@ -0,0 +1,126 @@
+package httpx
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/cmstar/go-errx"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecoverer(t *testing.T) {
+	var reported []errx.StackfulError
+	var logged []string
+
+	rc := NewRecoverer()
+	rc.Responder = NewResponder()
+	rc.Responder.Log = func(r *http.Request, status int, description string) {
+		logged = append(logged, description)
+	}
+	rc.Report = func(r *http.Request, err errx.StackfulError) {
+		reported = append(reported, err)
+	}
+
+	serve := func(h http.HandlerFunc) *httptest.ResponseRecorder {
+		reported, logged = nil, nil
+		w := httptest.NewRecorder()
+		rc.Wrap(h).ServeHTTP(w, httptest.NewRequest("GET", "/p", nil))
+		return w
+	}
+
+	t.Run("ok", func(t *testing.T) {
+		w := serve(func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte("ok"))
+		})
+		require.Equal(t, http.StatusOK, w.Code)
+		require.Equal(t, "ok", w.Body.String())
+		require.Nil(t, reported)
+		require.Nil(t, logged)
+	})
+
+	t.Run("panic", func(t *testing.T) {
+		w := serve(func(w http.ResponseWriter, r *http.Request) {
+			panic("secret")
+		})
+		require.Equal(t, http.StatusInternalServerError, w.Code)
+		require.Equal(t, ProblemContentType, w.Header().Get("Content-Type"))
+		require.NotContains(t, w.Body.String(), "secret")
+
+		require.Len(t, reported, 1)
+		require.Equal(t, "panic serving GET /p: secret", reported[0].ErrorWithoutStack())
+		require.Regexp(t, `recover_test\.go`, reported[0].Stack())
+
+		require.Len(t, logged, 1)
+		require.Contains(t, logged[0], "secret")
+	})
+
+	t.Run("biz", func(t *testing.T) {
+		w := serve(func(w http.ResponseWriter, r *http.Request) {
+			panic(errx.NewBizError(1, "bad input", nil))
+		})
+		require.Equal(t, http.StatusBadRequest, w.Code)
+		require.JSONEq(t, `{"title":"Bad Request","status":400,"detail":"bad input","code":1}`, w.Body.String())
+		require.Len(t, reported, 1)
+	})
+
+	t.Run("partial", func(t *testing.T) {
+		w := serve(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusAccepted)
+			w.Write([]byte("partial"))
+			panic("gg")
+		})
+		require.Equal(t, http.StatusAccepted, w.Code)
+		require.Equal(t, "partial", w.Body.String())
+		require.Equal(t, "", w.Header().Get("Content-Type"))
+
+		require.Len(t, reported, 1)
+		require.Len(t, logged, 1)
+		require.Contains(t, logged[0], "gg")
+	})
+
+	t.Run("partial-write", func(t *testing.T) {
+		w := serve(func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte("partial"))
+			panic(errx.NewBizError(1, "bad input", nil))
+		})
+		require.Equal(t, http.StatusOK, w.Code)
+		require.Equal(t, "partial", w.Body.String())
+		require.Len(t, reported, 1)
+		require.Len(t, logged, 1)
+	})
+
+	t.Run("flush", func(t *testing.T) {
+		w := serve(func(w http.ResponseWriter, r *http.Request) {
+			w.(http.Flusher).Flush()
+			panic("gg")
+		})
+		require.True(t, w.Flushed)
+		require.Equal(t, "", w.Body.String())
+		require.Len(t, logged, 1)
+	})
+
+	t.Run("abort", func(t *testing.T) {
+		require.PanicsWithValue(t, http.ErrAbortHandler, func() {
+			serve(func(w http.ResponseWriter, r *http.Request) {
+				panic(http.ErrAbortHandler)
+			})
+		})
+		require.Nil(t, reported)
+		require.Nil(t, logged)
+	})
+}
+
+func TestRecover(t *testing.T) {
+	h := Recover(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		panic(errx.NewBizError(1, "m", nil))
+	}))
+
+	old := DefaultResponder.Log
+	defer func() { DefaultResponder.Log = old }()
+	DefaultResponder.Log = nil
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+	require.Equal(t, http.StatusBadRequest, w.Code)
+}
@@ -0,0 +1,96 @@
+// Package httpx 提供 errx 与 net/http 的集成：将错误映射为 HTTP 状态码，
+// 并以 RFC 7807 的 application/problem+json 格式输出，同时对客户端隐藏内部错误和调用栈。
+package httpx
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/cmstar/go-errx"
+)
+
+// CodeRange 将错误码在 [Min, Max] 区间内的 BizError 映射为 Status 。
+type CodeRange struct {
+	Min    int
+	Max    int
+	Status int
+}
+
+// StatusMapper 将错误映射为 HTTP 状态码。错误链上有 BizError 时，按其错误码依次匹配：
+//   - Codes 中的错误码；
+//   - 在 Registry 中登记的错误码的 Category 对应的 Categories 中的状态码；
+//   - Ranges 中第一个包含该错误码的区间；
+//   - 以上都不匹配时，使用 BizStatus 。
+//
+// 错误链上没有 BizError 时，使用 ErrorStatus 。
+//
+// 通常应通过 NewStatusMapper() 创建，再修改需要调整的字段。字段在使用期间不应被修改。
+type StatusMapper struct {
+	// Codes 指定错误码到状态码的精确映射。
+	Codes map[int]int
+
+	// Registry 用于查询错误码的 Category ，为 nil 时不按 Category 匹配。
+	Registry *errx.Registry
+
+	// Categories 指定 Category 到状态码的映射。
+	Categories map[string]int
+
+	// Ranges 指定按错误码区间的映射，按顺序匹配。
+	Ranges []CodeRange
+
+	// BizStatus 是未匹配的 BizError 的状态码，默认为 400 。
+	BizStatus int
+
+	// ErrorStatus 是 BizError 以外的错误的状态码，默认为 500 。
+	ErrorStatus int
+}
+
+// NewStatusMapper 创建一个 StatusMapper ，其使用 errx.DefaultRegistry 查询 Category 。
+func NewStatusMapper() *StatusMapper {
+	return &StatusMapper{
+		Registry:    errx.DefaultRegistry,
+		BizStatus:   http.StatusBadRequest,
+		ErrorStatus: http.StatusInternalServerError,
+	}
+}
+
+// Status 返回给定错误对应的 HTTP 状态码。规则见 StatusMapper 。
+func (m *StatusMapper) Status(err error) int {
+	biz, ok := findBizError(err)
+	if !ok {
+		return m.ErrorStatus
+	}
+	return m.CodeStatus(biz.Code())
+}
+
+// CodeStatus 返回给定错误码的 BizError 对应的 HTTP 状态码。规则见 StatusMapper 。
+func (m *StatusMapper) CodeStatus(code int) int {
+	if status, ok := m.Codes[code]; ok {
+		return status
+	}
+
+	if m.Registry != nil && len(m.Categories) > 0 {
+		if def, ok := m.Registry.Lookup(code); ok {
+			if status, ok := m.Categories[def.Category]; ok {
+				return status
+			}
+		}
+	}
+
+	for _, r := range m.Ranges {
+		if code >= r.Min && code <= r.Max {
+			return r.Status
+		}
+	}
+
+	return m.BizStatus
+}
+
+// findBizError 返回错误链上的第一个 BizError 。
+func findBizError(err error) (errx.BizError, bool) {
+	var biz errx.BizError
+	if errors.As(err, &biz) {
+		return biz, true
+	}
+	return nil, false
+}
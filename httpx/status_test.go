@@ -0,0 +1,57 @@
+package httpx
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/cmstar/go-errx"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStatusMapper(t *testing.T) {
+	reg := errx.NewRegistry()
+	reg.MustRegister(
+		errx.CodeDef{Code: 1, Category: "auth"},
+		errx.CodeDef{Code: 2, Category: "other"},
+		errx.CodeDef{Code: 101, Category: "auth"},
+	)
+
+	m := NewStatusMapper()
+	m.Registry = reg
+	m.Codes = map[int]int{101: http.StatusForbidden}
+	m.Categories = map[string]int{"auth": http.StatusUnauthorized}
+	m.Ranges = []CodeRange{
+		{Min: 2, Max: 99, Status: http.StatusConflict},
+		{Min: 50, Max: 200, Status: http.StatusNotFound},
+	}
+
+	cases := []struct {
+		err  error
+		want int
+	}{
+		{errors.New("gg"), http.StatusInternalServerError},
+		{errx.Wrap("p", errors.New("gg")), http.StatusInternalServerError},
+		{errx.NewBizError(101, "m", nil), http.StatusForbidden},
+		{errx.NewBizError(1, "m", nil), http.StatusUnauthorized},
+		{errx.NewBizError(2, "m", nil), http.StatusConflict},
+		{errx.NewBizError(60, "m", nil), http.StatusConflict},
+		{errx.NewBizError(100, "m", nil), http.StatusNotFound},
+		{errx.NewBizError(300, "m", nil), http.StatusBadRequest},
+		{fmt.Errorf("p: %w", errx.NewBizError(1, "m", nil)), http.StatusUnauthorized},
+	}
+
+	for i, c := range cases {
+		t.Run(fmt.Sprint(i), func(t *testing.T) {
+			require.Equal(t, c.want, m.Status(c.err))
+		})
+	}
+
+	t.Run("no-registry", func(t *testing.T) {
+		m := NewStatusMapper()
+		m.Registry = nil
+		m.Categories = map[string]int{"auth": http.StatusUnauthorized}
+		require.Equal(t, http.StatusBadRequest, m.Status(errx.NewBizError(1, "m", nil)))
+	})
+}
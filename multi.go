@@ -0,0 +1,156 @@
+package errx
+
+import "fmt"
+
+// Multi 是一个 StackfulError ，用于聚合多个错误，如批量校验时一次返回所有的错误。
+// 它实现 Unwrap() []error ，可被 Describe() 逐个展示，每个错误保留其自身的调用栈和 BizError 错误码。
+//
+// 通常通过 NewMulti() 创建，此时记录创建处的调用栈；零值的 Multi 也可以直接使用，但不带有调用栈。
+// Multi 不是并发安全的。
+//
+// 以下方法可以在 nil 上调用，表示没有错误： Append() 、 Len() 、 Errors() 、 Unwrap() 、 Cause() 、
+// ErrorOrNil() 、 Error() 和 ErrorWithoutStack() 。其他方法，如 With() 、 Stack() ，不能在 nil 上调用。
+type Multi struct {
+	ErrorStack
+	ErrorFields
+	msg  string
+	errs []error
+}
+
+var _ StackfulError = (*Multi)(nil)
+var _ StackTracer = (*Multi)(nil)
+var _ FieldCarrier = (*Multi)(nil)
+var _ FieldAttacher = (*Multi)(nil)
+var _ fmt.Formatter = (*Multi)(nil)
+
+// NewMulti 创建一个 Multi ，并记录调用栈。 message 是这组错误的描述，可以为空。
+// 是否记录调用栈由调用栈记录策略决定，默认总是记录，见 SetCapturePolicy() 。
+func NewMulti(message string) *Multi {
+	return &Multi{
+		ErrorStack: captureStack(CaptureInfo{Kind: CaptureMulti}, 3), // 调用栈不包括当前函数。
+		msg:        message,
+	}
+}
+
+// Append 添加错误，其中的 nil 会被忽略。返回当前实例，以便链式调用。
+//
+// 可以在 nil 上调用：若给定的错误中有非 nil 的，创建一个新的 Multi 并返回，此时记录调用 Append() 处的调用栈；
+// 否则返回 nil 。因此在 nil 上调用时应使用其返回值：
+//
+//	var errs *errx.Multi
+//	errs = errs.Append(err)
+func (m *Multi) Append(errs ...error) *Multi {
+	for _, e := range errs {
+		if e == nil {
+			continue
+		}
+
+		if m == nil {
+			m = &Multi{
+				ErrorStack: captureStack(CaptureInfo{Kind: CaptureMulti}, 3), // 调用栈不包括当前函数。
+			}
+		}
+		m.errs = append(m.errs, e)
+	}
+	return m
+}
+
+// Len 返回错误的数量。可以在 nil 上调用，返回 0 。
+func (m *Multi) Len() int {
+	if m == nil {
+		return 0
+	}
+	return len(m.errs)
+}
+
+// Errors 返回所有的错误，按添加的顺序排列。返回的是一个副本，对其修改不影响当前实例。
+func (m *Multi) Errors() []error {
+	if m.Len() == 0 {
+		return nil
+	}
+
+	res := make([]error, len(m.errs))
+	copy(res, m.errs)
+	return res
+}
+
+// Unwrap 返回所有的错误，以支持 errors.Is() 和 errors.As() （ Go 1.20 及以上）。返回值不应被修改。
+// 可以在 nil 上调用，返回 nil 。
+func (m *Multi) Unwrap() []error {
+	if m == nil {
+		return nil
+	}
+	return m.errs
+}
+
+// Cause 实现 StackfulError.Cause() 。 Multi 的内部错误有多个，没有单一的 Cause ，总是返回 nil ，应使用 Errors() 。
+func (m *Multi) Cause() error {
+	return nil
+}
+
+// With 实现 FieldAttacher.With() 。返回的 Multi 与当前实例互不影响，对其 Append() 不会修改当前实例。
+func (m *Multi) With(key string, value interface{}) StackfulError {
+	res := *m
+	res.ErrorFields = m.ErrorFields.with(Field{key, value})
+	res.errs = m.Errors()
+	return &res
+}
+
+// ErrorOrNil 若没有错误（包括在 nil 上调用），返回 nil ；否则返回当前实例。
+// 用于在函数末尾返回：
+//
+//	return errs.ErrorOrNil()
+func (m *Multi) ErrorOrNil() error {
+	if m.Len() == 0 {
+		return nil
+	}
+	return m
+}
+
+// Error 返回以 Describe() 的格式输出错误信息，每个错误作为一个分支展示。可以在 nil 上调用，返回空字符串。
+func (m *Multi) Error() string {
+	if m == nil {
+		return ""
+	}
+	return Describe(m)
+}
+
+// ErrorWithoutStack 实现 StackfulError.ErrorWithoutStack() ，格式为： message: err1; err2; ... 。
+// message 为空时，前置的“message: ”部分被省略。各个错误使用 ErrorWithoutStack() 或 Error() 。
+// 与 ErrorWrapper 一样，错误链中的环和超出 DefaultMaxDepth 限制的部分以标记代替。
+// 可以在 nil 上调用，返回空字符串。
+func (m *Multi) ErrorWithoutStack() string {
+	if m == nil {
+		return ""
+	}
+	return messageWithoutStack(m)
+}
+
+// appendMessage 将 ErrorWithoutStack() 追加到 b ，规则见 appendMessage() 函数。 m 本身已在 visited 中。
+func (m *Multi) appendMessage(b []byte, visited errorSet, depth int) []byte {
+	start := len(b)
+	b = append(b, m.msg...)
+
+	for i, e := range m.errs {
+		if i == 0 {
+			if len(b) > start {
+				b = append(b, ": "...)
+			}
+		} else {
+			b = append(b, "; "...)
+		}
+
+		b = appendMessage(b, e, visited, depth+1)
+	}
+	return b
+}
+
+// MarshalJSON 实现 json.Marshaler ，输出整个错误链，各个错误记录在 ChainLayer.Branches 中，格式见 ErrorChain 。
+func (m *Multi) MarshalJSON() ([]byte, error) {
+	return MarshalChain(m)
+}
+
+// Format 实现 fmt.Formatter.Formats() ，规则与 ErrorWrapper.Format() 相同。
+func (m *Multi) Format(f fmt.State, verb rune) {
+	formatStackful(f, verb, m)
+}
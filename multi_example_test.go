@@ -0,0 +1,40 @@
+package errx_test
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/cmstar/go-errx"
+)
+
+func ExampleMulti() {
+	// 当前示例演示如何通过 Multi 一次返回多个错误。
+
+	validate := func(names []string) error {
+		errs := errx.NewMulti("invalid names")
+		for i, name := range names {
+			if name == "" {
+				errs.Append(errx.NewBizError(1, fmt.Sprintf("names[%d] is empty", i), nil))
+			}
+		}
+		return errs.ErrorOrNil()
+	}
+
+	fmt.Println(validate([]string{"a", "b"}))
+
+	err := validate([]string{"", "b", ""})
+	fmt.Printf("%s\n", err)
+
+	var multi *errx.Multi
+	if errors.As(err, &multi) {
+		for _, e := range multi.Errors() {
+			fmt.Println(e.(errx.BizError).Code(), e.(errx.BizError).Message())
+		}
+	}
+
+	// Output:
+	// <nil>
+	// invalid names: (1) names[0] is empty; (1) names[2] is empty
+	// 1 names[0] is empty
+	// 1 names[2] is empty
+}
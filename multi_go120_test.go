@@ -0,0 +1,23 @@
+//go:build go1.20
+
+package errx
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// Go 1.20 起， errors.Is() 和 errors.As() 支持 Unwrap() []error 。
+func TestMulti_isAs(t *testing.T) {
+	a := errors.New("a")
+	m := NewMulti("msg").Append(Wrap("w", a), NewBizError(2, "b", nil))
+	err := Wrap("outer", m.ErrorOrNil())
+
+	require.True(t, errors.Is(err, a))
+
+	var biz BizError
+	require.True(t, errors.As(err, &biz))
+	require.Equal(t, 2, biz.Code())
+}
@@ -0,0 +1,87 @@
+package errx
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMulti(t *testing.T) {
+	t.Run("nil", func(t *testing.T) {
+		var m *Multi
+		require.Equal(t, 0, m.Len())
+		require.Nil(t, m.Errors())
+		require.Nil(t, m.ErrorOrNil())
+		require.Nil(t, m.Unwrap())
+		require.Nil(t, m.Cause())
+		require.Equal(t, "", m.Error())
+		require.Equal(t, "", m.ErrorWithoutStack())
+
+		// 在 nil 上 Append() 创建新的实例，并记录调用 Append() 处的调用栈。
+		require.Nil(t, m.Append(nil))
+		m = m.Append(nil, errors.New("a"))
+		require.Equal(t, 1, m.Len())
+		require.Equal(t, "a", m.ErrorWithoutStack())
+		require.Regexp(t, `^\[.+multi_test\.go:\d+\] go-errx\.TestMulti\.func1\n`, m.Stack())
+	})
+
+	t.Run("empty", func(t *testing.T) {
+		m := NewMulti("msg")
+		require.Equal(t, 0, m.Len())
+		require.Nil(t, m.Errors())
+		require.Nil(t, m.Unwrap())
+		require.Nil(t, m.ErrorOrNil())
+		require.Equal(t, "msg", m.ErrorWithoutStack())
+	})
+
+	t.Run("zero", func(t *testing.T) {
+		var m Multi
+		m.Append(errors.New("a"))
+		require.Equal(t, "a", m.ErrorWithoutStack())
+		require.Equal(t, "", m.Stack())
+		require.Equal(t, "a\n=== [1/1] a\n", m.Error())
+	})
+
+	t.Run("append", func(t *testing.T) {
+		a := errors.New("a")
+		b := NewBizError(2, "b", nil)
+		m := NewMulti("msg").Append(a, nil).Append(b)
+		require.Equal(t, 2, m.Len())
+		require.Equal(t, []error{a, b}, m.Errors())
+		require.Equal(t, []error{a, b}, m.Unwrap())
+		require.Nil(t, m.Cause())
+		require.Equal(t, m, m.ErrorOrNil())
+		require.Equal(t, "msg: a; (2) b", m.ErrorWithoutStack())
+
+		// Errors() 返回副本。
+		m.Errors()[0] = nil
+		require.Equal(t, a, m.Errors()[0])
+	})
+
+	t.Run("stack", func(t *testing.T) {
+		m := NewMulti("")
+		require.Regexp(t, `^\[.+multi_test\.go:\d+\] go-errx\.TestMulti\.func5\n`, m.Stack())
+		require.NotEmpty(t, m.Frames())
+	})
+
+	t.Run("describe", func(t *testing.T) {
+		m := NewMulti("validate").
+			Append(Wrap("a", nil)).
+			Append(NewBizError(2, "b", errors.New("c")))
+
+		res := Describe(Wrap("outer", m))
+		require.Regexp(t, `^outer: validate: a; \(2\) b\n--- \[.+multi_test\.go:\d+\] go-errx\.TestMulti\.func6\n`, res)
+		require.Regexp(t, `\n=== validate: a; \(2\) b\n--- \[.+multi_test\.go:\d+\] go-errx\.TestMulti\.func6\n`, res)
+		require.Regexp(t, `\n=== \[1/2\] a\n    --- \[.+multi_test\.go:\d+\] go-errx\.TestMulti\.func6\n    \.\.\. \d+ frames in common with above\n`, res)
+		require.Regexp(t, `\n=== \[2/2\] \(2\) b\n    --- \[.+multi_test\.go:\d+\] go-errx\.TestMulti\.func6\n    \.\.\. \d+ frames in common with above\n    === c\n$`, res)
+	})
+
+	t.Run("format", func(t *testing.T) {
+		m := NewMulti("msg").Append(errors.New("a"))
+		require.Equal(t, "msg: a", fmt.Sprintf("%s", m))
+		require.Equal(t, `"msg: a"`, fmt.Sprintf("%q", m))
+		require.Equal(t, m.Error(), fmt.Sprintf("%v", m))
+	})
+}
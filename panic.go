@@ -0,0 +1,136 @@
+package errx
+
+import (
+	"errors"
+	"fmt"
+	"runtime"
+	"strings"
+)
+
+// PanicError 是 PreserveRecover() 、 Run() 、 RunE() 从 panic 中恢复得到的错误。
+// 可通过 Value() 获取 panic 的原始数据，通过 IsPanic() 判断错误是否来自 panic 。
+//
+// 其描述与 ErrorWrapper 相同，格式为： 前缀: panic 的数据 。其调用栈从 panic 发生的位置开始。
+// 若 panic 的数据是 error ，它即是 Cause() ；否则 Cause() 是以 fmt.Sprint() 的结果作为描述的 error 。
+type PanicError struct {
+	ErrorWrapper
+	value interface{}
+}
+
+var _ StackfulError = (*PanicError)(nil)
+var _ StackTracer = (*PanicError)(nil)
+var _ FieldCarrier = (*PanicError)(nil)
+var _ FieldAttacher = (*PanicError)(nil)
+var _ fmt.Formatter = (*PanicError)(nil)
+
+// Value 返回 panic 的原始数据，即 recover() 的返回值。
+func (e *PanicError) Value() interface{} {
+	return e.value
+}
+
+// Error 返回以 Describe() 的格式输出错误信息。
+func (e *PanicError) Error() string {
+	return Describe(e)
+}
+
+// With 实现 FieldAttacher.With() ，返回的仍是 PanicError 。
+func (e *PanicError) With(key string, value interface{}) StackfulError {
+	res := *e
+	res.ErrorFields = e.ErrorFields.with(Field{key, value})
+	return &res
+}
+
+// Format 实现 fmt.Formatter ，与 ErrorWrapper 相同。
+func (e *PanicError) Format(f fmt.State, verb rune) {
+	formatStackful(f, verb, e)
+}
+
+// MarshalJSON 实现 json.Marshaler ，输出整个错误链，格式见 ErrorChain 。
+func (e *PanicError) MarshalJSON() ([]byte, error) {
+	return MarshalChain(e)
+}
+
+// IsPanic 判断错误链上是否有 PanicError ，即错误是否来自 panic 。
+func IsPanic(err error) bool {
+	var pe *PanicError
+	return errors.As(err, &pe)
+}
+
+// PreserveRecover 用于封装从 panic 中 recover 的数据，返回 *PanicError 。若 recovered 为 nil ，返回 nil 。
+// 此方法的调用应放在 defer 过程里。
+// 是否记录调用栈由调用栈记录策略决定，默认总是记录，见 SetCapturePolicy() 。
+// 记录的调用栈从 panic 发生的位置开始，不包括 defer 的函数和 runtime 内部处理 panic 的部分。
+// 包级别的策略（见 SetPackageCapturePolicy() ）按调用此方法的函数所在的包判断。
+func PreserveRecover(message string, recovered interface{}) StackfulError {
+	if recovered == nil {
+		return nil
+	}
+	return recoverPanic(message, recovered, capturePackage(3), 4) // 忽略当前函数和 defer 的函数。
+}
+
+// recoverPanic 实现 PreserveRecover() 。 pkg 是判断包级别的调用栈记录策略所用的包路径，见 capturePackage() 。
+// skip 的含义与 captureStack() 相同，但从调用 recoverPanic 的函数算起。
+func recoverPanic(message string, recovered interface{}, pkg string, skip int) StackfulError {
+	if recovered == nil {
+		return nil
+	}
+
+	var cause error
+	switch e := recovered.(type) {
+	case error:
+		cause = e
+	case string:
+		cause = errors.New(e)
+	default:
+		// panic 的不是 error 和字符串也应该是个能转成字符串的东西。
+		cause = fmt.Errorf("%v", e)
+	}
+
+	st := captureStack(CaptureInfo{Kind: CaptureRecover, Package: pkg, Cause: cause}, skip+1) // 忽略当前函数。
+	return &PanicError{
+		ErrorWrapper: ErrorWrapper{
+			ErrorCause: ErrorCause{cause},
+			ErrorStack: trimPanicFrames(st),
+			msg:        message,
+		},
+		value: recovered,
+	}
+}
+
+// trimPanicFrames 去掉调用栈中 runtime.gopanic 及其之前的部分，使调用栈从 panic 发生的位置开始。
+// 对于 runtime 产生的 panic （如空指针），还去掉 gopanic 之后的 runtime.panicmem 、 runtime.sigpanic 等。
+// 若调用栈中没有 runtime.gopanic （如不是在 panic 过程中调用 PreserveRecover ），原样返回。
+//
+// 仅查找每个 PC 所在的函数，不需要完整地解析调用栈，开销很小。
+func trimPanicFrames(e ErrorStack) ErrorStack {
+	if e.st == nil {
+		return e
+	}
+
+	pcs := e.st.pcs
+	start := -1
+	for i, pc := range pcs {
+		if funcNameForPC(pc) == "runtime.gopanic" {
+			start = i + 1
+			break
+		}
+	}
+	if start < 0 {
+		return e
+	}
+
+	for start < len(pcs) && strings.HasPrefix(funcNameForPC(pcs[start]), "runtime.") {
+		start++
+	}
+	return ErrorStack{&stack{pcs: pcs[start:]}}
+}
+
+// funcNameForPC 返回 runtime.Callers() 得到的 PC 所在的函数的名称。
+func funcNameForPC(pc uintptr) string {
+	// 得到的 PC 是调用的下一条指令，减一才落在调用指令上。
+	f := runtime.FuncForPC(pc - 1)
+	if f == nil {
+		return ""
+	}
+	return f.Name()
+}
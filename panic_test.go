@@ -0,0 +1,91 @@
+package errx
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type panicValue struct {
+	N int
+}
+
+func TestPanicError(t *testing.T) {
+	recoverFrom := func(f func()) (err StackfulError) {
+		defer func() {
+			err = PreserveRecover("prefix", recover())
+		}()
+		f()
+		return nil
+	}
+
+	t.Run("value", func(t *testing.T) {
+		err := recoverFrom(func() { panic(panicValue{12}) })
+		pe, ok := err.(*PanicError)
+		require.True(t, ok)
+		require.Equal(t, panicValue{12}, pe.Value())
+		require.Equal(t, "prefix: {12}", pe.ErrorWithoutStack())
+		require.Equal(t, "{12}", pe.Cause().Error())
+		require.True(t, IsPanic(err))
+		require.True(t, IsPanic(Wrap("outer", err)))
+	})
+
+	t.Run("error", func(t *testing.T) {
+		cause := errors.New("gg")
+		err := recoverFrom(func() { panic(cause) })
+		require.Equal(t, cause, err.(*PanicError).Value())
+		require.Equal(t, cause, err.Cause())
+		require.True(t, errors.Is(err, cause))
+	})
+
+	t.Run("string", func(t *testing.T) {
+		err := recoverFrom(func() { panic("100%") })
+		require.Equal(t, "prefix: 100%", err.ErrorWithoutStack())
+	})
+
+	t.Run("panic-site", func(t *testing.T) {
+		err := recoverFrom(func() { panic(1) })
+		frames := err.(StackTracer).Frames()
+		require.Equal(t, "github.com/cmstar/go-errx.TestPanicError.func5.1", frames[0].Function)
+		require.Equal(t, "github.com/cmstar/go-errx.TestPanicError.func1", frames[1].Function)
+	})
+
+	t.Run("runtime-panic", func(t *testing.T) {
+		err := recoverFrom(func() {
+			var p *panicValue
+			fmt.Println(p.N)
+		})
+		frames := err.(StackTracer).Frames()
+		require.Equal(t, "github.com/cmstar/go-errx.TestPanicError.func6.1", frames[0].Function)
+	})
+
+	t.Run("not-panicking", func(t *testing.T) {
+		err := PreserveRecover("prefix", 1)
+		require.Equal(t, "prefix: 1", err.ErrorWithoutStack())
+		require.NotEmpty(t, err.(StackTracer).Frames())
+	})
+
+	t.Run("with", func(t *testing.T) {
+		err := With(recoverFrom(func() { panic(1) }), "k", "v")
+		require.Equal(t, 1, err.(*PanicError).Value())
+		require.Equal(t, map[string]interface{}{"k": "v"}, Fields(err))
+	})
+
+	t.Run("not-panic", func(t *testing.T) {
+		require.False(t, IsPanic(nil))
+		require.False(t, IsPanic(Wrap("p", errors.New("gg"))))
+	})
+}
+
+func TestRun_PanicError(t *testing.T) {
+	err := Run(func() { panic(panicValue{1}) })
+	require.Equal(t, panicValue{1}, err.(*PanicError).Value())
+
+	err = RunE(func() error { panic(panicValue{2}) })
+	require.Equal(t, panicValue{2}, err.(*PanicError).Value())
+
+	err = RunE(func() error { return errors.New("gg") })
+	require.False(t, IsPanic(err))
+}
@@ -0,0 +1,244 @@
+package errx
+
+import (
+	"runtime"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// CaptureKind 表示正在创建的错误的来源，即由哪个方法创建。
+type CaptureKind int
+
+const (
+	// CaptureWrap 表示由 Wrap() 创建的错误。
+	CaptureWrap CaptureKind = iota + 1
+
+	// CaptureBizError 表示由 NewBizError() 创建的错误。
+	CaptureBizError
+
+	// CaptureRecover 表示由 PreserveRecover() 创建的错误。
+	CaptureRecover
+
+	// CaptureMulti 表示由 NewMulti() 创建的错误。
+	CaptureMulti
+
+	// CaptureGo 表示 Go() 、 GoE() 或 Group.Go() 在启动 goroutine 时记录的调用栈，
+	// 此时错误尚未产生， CaptureInfo.Cause 为 nil 。
+	CaptureGo
+)
+
+// CaptureInfo 描述一个正在创建的错误，供 CapturePolicy 判断是否需要记录调用栈。
+type CaptureInfo struct {
+	// Kind 表示错误的来源。
+	Kind CaptureKind
+
+	// Package 是创建错误的函数（如调用 Wrap() 的函数）所在的包路径。
+	// 对于 PreserveRecover() ，是调用它的函数（通常是 defer 的函数）所在的包；
+	// 对于 Run() 、 RunE() 、 Go() 等，是调用这些方法的函数所在的包，而不是发生 panic 的位置。
+	// 获取包路径有一定的开销，仅在设置了包级别的策略时才会赋值，否则为空字符串。
+	Package string
+
+	// Cause 是引起此错误的错误，可能为 nil 。
+	Cause error
+
+	// Code 是 BizError 的错误码，仅当 Kind 为 CaptureBizError 时有意义。
+	Code int
+}
+
+// CapturePolicy 是调用栈的记录策略。 Wrap() 、 NewBizError() 、 PreserveRecover() 等在记录调用栈之前，
+// 通过此接口判断是否需要记录。可通过 SetCapturePolicy() 和 SetPackageCapturePolicy() 配置。
+//
+// 此接口的实现可能在多个 goroutine 中被同时调用，需要是并发安全的。
+type CapturePolicy interface {
+	// ShouldCapture 返回是否需要为给定的错误记录调用栈。
+	ShouldCapture(info CaptureInfo) bool
+}
+
+// CapturePolicyFunc 将一个函数转换为 CapturePolicy 。
+type CapturePolicyFunc func(info CaptureInfo) bool
+
+// ShouldCapture 实现 CapturePolicy.ShouldCapture() 。
+func (f CapturePolicyFunc) ShouldCapture(info CaptureInfo) bool {
+	return f(info)
+}
+
+// CaptureAlways 返回总是记录调用栈的策略。这也是默认的策略。
+func CaptureAlways() CapturePolicy {
+	return CapturePolicyFunc(func(CaptureInfo) bool { return true })
+}
+
+// CaptureNever 返回从不记录调用栈的策略。此时 Wrap() 等同于 WrapWithoutStack() 。
+func CaptureNever() CapturePolicy {
+	return CapturePolicyFunc(func(CaptureInfo) bool { return false })
+}
+
+// CaptureSampled 返回采样记录调用栈的策略：每 n 次调用中记录 1 次（第 1 次总是记录）。
+// 若 n <= 1 ，则总是记录。
+func CaptureSampled(n int) CapturePolicy {
+	if n <= 1 {
+		return CaptureAlways()
+	}
+
+	var counter uint64
+	return CapturePolicyFunc(func(CaptureInfo) bool {
+		c := atomic.AddUint64(&counter, 1)
+		return (c-1)%uint64(n) == 0
+	})
+}
+
+// CaptureIfCauseHasNoStack 返回仅在错误链中尚没有调用栈时才记录调用栈的策略。
+// 即沿着 Cause 逐层查找（包括 Unwrap() []error 的各个分支，见 Walk() ），若找到已记录调用栈的 StackfulError ，则不再记录。
+// 这样最内层的调用栈会被保留，外层的调用栈通常与其重叠，不再记录可以减少开销。
+func CaptureIfCauseHasNoStack() CapturePolicy {
+	return CapturePolicyFunc(func(info CaptureInfo) bool {
+		return !chainHasStack(info.Cause)
+	})
+}
+
+// CaptureBizCodes 返回仅为给定错误码的 BizError 记录调用栈的策略。
+// 对于其他来源（ Kind 不为 CaptureBizError ）的错误，总是不记录。
+func CaptureBizCodes(codes ...int) CapturePolicy {
+	set := make(map[int]struct{}, len(codes))
+	for _, c := range codes {
+		set[c] = struct{}{}
+	}
+
+	return CapturePolicyFunc(func(info CaptureInfo) bool {
+		if info.Kind != CaptureBizError {
+			return false
+		}
+		_, ok := set[info.Code]
+		return ok
+	})
+}
+
+// SetCapturePolicy 设置全局的调用栈记录策略。给定 nil 则恢复默认策略，即总是记录。
+// 包级别的策略（见 SetPackageCapturePolicy() ）优先于全局策略。
+func SetCapturePolicy(p CapturePolicy) {
+	captureConfigMu.Lock()
+	defer captureConfigMu.Unlock()
+
+	conf := loadCaptureConfig().clone()
+	conf.global = p
+	captureConfigValue.Store(conf)
+}
+
+// SetPackageCapturePolicy 为给定包路径（如 github.com/user/pkg ）下的代码设置调用栈记录策略，覆盖全局策略。
+// 策略同样作用于其子包，除非子包设置了自己的策略。给定 nil 则移除该包的策略。
+func SetPackageCapturePolicy(pkgPath string, p CapturePolicy) {
+	captureConfigMu.Lock()
+	defer captureConfigMu.Unlock()
+
+	conf := loadCaptureConfig().clone()
+	if p == nil {
+		delete(conf.packages, pkgPath)
+	} else {
+		conf.packages[pkgPath] = p
+	}
+	captureConfigValue.Store(conf)
+}
+
+// captureConfig 存放调用栈记录策略的配置。一经发布不再修改，修改配置时创建新的实例替换。
+type captureConfig struct {
+	global   CapturePolicy            // 为 nil 表示总是记录。
+	packages map[string]CapturePolicy // 包路径 -> 策略。
+}
+
+var (
+	captureConfigMu    sync.Mutex // 仅用于串行化写操作。
+	captureConfigValue atomic.Value
+)
+
+func loadCaptureConfig() *captureConfig {
+	conf, _ := captureConfigValue.Load().(*captureConfig)
+	if conf == nil {
+		return &captureConfig{}
+	}
+	return conf
+}
+
+func (c *captureConfig) clone() *captureConfig {
+	res := &captureConfig{
+		global:   c.global,
+		packages: make(map[string]CapturePolicy, len(c.packages)+1),
+	}
+	for k, v := range c.packages {
+		res.packages[k] = v
+	}
+	return res
+}
+
+// policyFor 返回给定包路径适用的策略：优先使用最接近的包级别策略，否则使用全局策略。
+func (c *captureConfig) policyFor(pkgPath string) CapturePolicy {
+	for p := pkgPath; p != ""; {
+		if policy, ok := c.packages[p]; ok {
+			return policy
+		}
+
+		idx := strings.LastIndex(p, "/")
+		if idx < 0 {
+			break
+		}
+		p = p[:idx]
+	}
+	return c.global
+}
+
+// captureStack 根据调用栈记录策略，决定是否调用 GetErrorStack() 。
+// skip 的含义与 GetErrorStack() 相同，但从调用 captureStack 的函数算起，即调用方传给 GetErrorStack() 的值。
+// 若 info.Package 已赋值（见 capturePackage() ），则使用它判断包级别的策略，否则根据 skip 获取。
+func captureStack(info CaptureInfo, skip int) ErrorStack {
+	conf := loadCaptureConfig()
+
+	policy := conf.global
+	if len(conf.packages) > 0 {
+		if info.Package == "" {
+			info.Package = callerPackage(skip + 1)
+		}
+		policy = conf.policyFor(info.Package)
+	}
+
+	if policy != nil && !policy.ShouldCapture(info) {
+		return ErrorStack{}
+	}
+	return GetErrorStack(skip + 1)
+}
+
+// capturePackage 在设置了包级别的调用栈记录策略时，返回调用方所在的包路径，否则返回空字符串。
+// skip 的含义与 captureStack() 相同。
+// 用于调用栈的起点与判断策略所用的包不在同一处的情况，如 panic 时调用栈从 panic 的位置开始。
+func capturePackage(skip int) string {
+	if len(loadCaptureConfig().packages) == 0 {
+		return ""
+	}
+	return callerPackage(skip + 1)
+}
+
+// callerPackage 返回调用方所在的包路径， skip 的含义与 GetErrorStack() 相同。
+func callerPackage(skip int) string {
+	var pcs [1]uintptr
+	if runtime.Callers(skip, pcs[:]) == 0 {
+		return ""
+	}
+
+	f, _ := runtime.CallersFrames(pcs[:]).Next()
+	return packageName(f.Function)
+}
+
+// chainHasStack 判断给定的错误链中是否有已记录调用栈的 StackfulError 。错误链中有环时也能正常结束。
+func chainHasStack(err error) bool {
+	found := false
+	Walk(err, func(_ int, e error) bool {
+		switch e := e.(type) {
+		case interface{ hasStack() bool }:
+			found = e.hasStack()
+
+		case StackfulError:
+			// 非本包的实现，只能通过 Stack() 判断。
+			found = e.Stack() != ""
+		}
+		return !found
+	})
+	return found
+}
@@ -0,0 +1,181 @@
+package errx
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// resetCapturePolicy 清除所有调用栈记录策略，用于测试结束后恢复默认状态。
+func resetCapturePolicy() {
+	captureConfigMu.Lock()
+	defer captureConfigMu.Unlock()
+	captureConfigValue.Store(&captureConfig{})
+}
+
+func TestCapturePolicies(t *testing.T) {
+	t.Run("always", func(t *testing.T) {
+		require.True(t, CaptureAlways().ShouldCapture(CaptureInfo{}))
+	})
+
+	t.Run("never", func(t *testing.T) {
+		require.False(t, CaptureNever().ShouldCapture(CaptureInfo{}))
+	})
+
+	t.Run("sampled", func(t *testing.T) {
+		p := CaptureSampled(3)
+		var got []bool
+		for i := 0; i < 7; i++ {
+			got = append(got, p.ShouldCapture(CaptureInfo{}))
+		}
+		require.Equal(t, []bool{true, false, false, true, false, false, true}, got)
+
+		p = CaptureSampled(0)
+		require.True(t, p.ShouldCapture(CaptureInfo{}))
+		require.True(t, p.ShouldCapture(CaptureInfo{}))
+	})
+
+	t.Run("if-cause-has-no-stack", func(t *testing.T) {
+		p := CaptureIfCauseHasNoStack()
+		require.True(t, p.ShouldCapture(CaptureInfo{}))
+		require.True(t, p.ShouldCapture(CaptureInfo{Cause: errors.New("e")}))
+		require.True(t, p.ShouldCapture(CaptureInfo{Cause: WrapWithoutStack("p", errors.New("e"))}))
+		require.False(t, p.ShouldCapture(CaptureInfo{Cause: Wrap("p", errors.New("e"))}))
+		require.False(t, p.ShouldCapture(CaptureInfo{Cause: WrapWithoutStack("p", NewBizError(1, "b", nil))}))
+
+		// 还原自文本形式的调用栈的 RemoteError 也有调用栈。
+		require.False(t, p.ShouldCapture(CaptureInfo{Cause: ErrorChain{{Message: "m", RawStack: "stack"}}.Err()}))
+		require.True(t, p.ShouldCapture(CaptureInfo{Cause: ErrorChain{{Message: "m"}}.Err()}))
+	})
+
+	t.Run("biz-codes", func(t *testing.T) {
+		p := CaptureBizCodes(1, 3)
+		require.True(t, p.ShouldCapture(CaptureInfo{Kind: CaptureBizError, Code: 1}))
+		require.False(t, p.ShouldCapture(CaptureInfo{Kind: CaptureBizError, Code: 2}))
+		require.True(t, p.ShouldCapture(CaptureInfo{Kind: CaptureBizError, Code: 3}))
+		require.False(t, p.ShouldCapture(CaptureInfo{Kind: CaptureWrap, Code: 1}))
+	})
+}
+
+func TestSetCapturePolicy(t *testing.T) {
+	defer resetCapturePolicy()
+
+	var infos []CaptureInfo
+	SetCapturePolicy(CapturePolicyFunc(func(info CaptureInfo) bool {
+		infos = append(infos, info)
+		return false
+	}))
+
+	cause := errors.New("cause")
+	require.Equal(t, "", Wrap("msg", cause).Stack())
+	require.Equal(t, "", NewBizError(12, "msg", cause).Stack())
+
+	err := func() (err error) {
+		defer func() {
+			err = PreserveRecover("msg", recover())
+		}()
+		panic(cause)
+	}()
+	require.Equal(t, "", err.(StackfulError).Stack())
+
+	require.Equal(t, []CaptureInfo{
+		{Kind: CaptureWrap, Cause: cause},
+		{Kind: CaptureBizError, Cause: cause, Code: 12},
+		{Kind: CaptureRecover, Cause: cause},
+	}, infos)
+
+	// 恢复默认策略。
+	SetCapturePolicy(nil)
+	require.NotEqual(t, "", Wrap("msg", cause).Stack())
+}
+
+func TestSetPackageCapturePolicy(t *testing.T) {
+	defer resetCapturePolicy()
+
+	const pkg = "github.com/cmstar/go-errx"
+
+	var info CaptureInfo
+	SetPackageCapturePolicy(pkg, CapturePolicyFunc(func(i CaptureInfo) bool {
+		info = i
+		return false
+	}))
+	require.Equal(t, "", Wrap("msg", nil).Stack())
+	require.Equal(t, pkg, info.Package)
+
+	// 包级别的策略优先于全局策略。
+	SetCapturePolicy(CaptureAlways())
+	require.Equal(t, "", Wrap("msg", nil).Stack())
+
+	// 不相关的包不受影响。
+	SetPackageCapturePolicy(pkg, nil)
+	SetPackageCapturePolicy("github.com/cmstar/go-errx/sub", CaptureNever())
+	SetPackageCapturePolicy("github.com/cmstar/go", CaptureNever())
+	require.NotEqual(t, "", Wrap("msg", nil).Stack())
+
+	// 父级路径的策略作用于子包。
+	SetPackageCapturePolicy("github.com/cmstar", CaptureNever())
+	require.Equal(t, "", Wrap("msg", nil).Stack())
+
+	// 更具体的路径优先。
+	SetPackageCapturePolicy(pkg, CaptureAlways())
+	require.Regexp(t, `^\[.+policy_test\.go:\d+\] go-errx\.TestSetPackageCapturePolicy`, Wrap("msg", nil).Stack())
+}
+
+func TestCaptureConfig_policyFor(t *testing.T) {
+	c := &captureConfig{
+		global: CaptureAlways(),
+		packages: map[string]CapturePolicy{
+			"a/b": CaptureNever(),
+		},
+	}
+
+	check := func(pkgPath string, want bool) {
+		require.Equal(t, want, c.policyFor(pkgPath).ShouldCapture(CaptureInfo{}), pkgPath)
+	}
+	check("", true)
+	check("a", true)
+	check("a/bc", true)
+	check("a/b", false)
+	check("a/b/c/d", false)
+}
+
+func TestSetPackageCapturePolicy_recover(t *testing.T) {
+	defer resetCapturePolicy()
+
+	const pkg = "github.com/cmstar/go-errx"
+
+	var infos []CaptureInfo
+	SetPackageCapturePolicy(pkg, CapturePolicyFunc(func(i CaptureInfo) bool {
+		infos = append(infos, i)
+		return false
+	}))
+
+	check := func(err error) {
+		require.IsType(t, (*PanicError)(nil), err)
+		require.Equal(t, "", err.(StackfulError).Stack())
+		require.Len(t, infos, 1)
+		require.Equal(t, CaptureRecover, infos[0].Kind)
+		require.Equal(t, pkg, infos[0].Package)
+		infos = nil
+	}
+
+	// 直接调用 PreserveRecover() ，按 defer 的函数所在的包判断，而不是 runtime 。
+	check(func() (err error) {
+		defer func() {
+			err = PreserveRecover("", recover())
+		}()
+		panic("gg")
+	}())
+
+	// Run() 和 RunE() 按调用它们的函数所在的包判断。
+	check(Run(func() { panic("gg") }))
+	check(RunE(func() error { panic("gg") }))
+
+	// 其他包的策略不影响当前包。
+	SetPackageCapturePolicy(pkg, nil)
+	SetPackageCapturePolicy("runtime", CaptureNever())
+	err := Run(func() { panic("gg") })
+	require.NotEqual(t, "", err.(StackfulError).Stack())
+	require.Empty(t, infos)
+}
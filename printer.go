@@ -0,0 +1,475 @@
+package errx
+
+import (
+	"errors"
+	"os"
+	"path"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"runtime/debug"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Printer 用于输出错误链的描述，可控制输出的内容和格式。 Describe() 即使用 NewPrinter() 得到的默认设置。
+//
+// 零值的 Printer 不输出任何分隔符，通常应通过 NewPrinter() 创建，再修改需要调整的字段。
+// Printer 的字段在使用期间不应被修改，一个设置好的 Printer 可以在多个 goroutine 中同时使用。
+type Printer struct {
+	// MaxDepth 限制输出的错误链的层数，超出的内层错误以一行“<truncated N more>”代替， N 是省略的层数。
+	// 多个错误的分支中的层数从外层开始累计。小于等于 0 表示不限制。 NewPrinter() 将其设为 DefaultMaxDepth 。
+	MaxDepth int
+
+	// MaxFrames 限制每一层错误输出的调用栈的 Frame 数量，超出的部分以一行“... N more frames”代替。
+	// 小于等于 0 表示不限制。
+	//
+	// 此字段及以下对调用栈的设置，对只有文本形式的调用栈（如非 StackTracer 的 StackfulError 和从 ChainLayer.RawStack
+	// 还原的 RemoteError ）同样生效：文本中的每一行若都是 Stack() 输出的“[file:line] func”的格式，则被解析为 Frame ；
+	// 否则仅能按行数限制 Frame 的数量，其他设置不生效。
+	MaxFrames int
+
+	// OmitStack 为 true 时不输出调用栈，也不输出 StackPrefix 。没有记录调用栈的错误总是不输出这两部分。
+	OmitStack bool
+
+	// OmitFields 为 true 时不输出附加在错误上的键值对，见 FieldCarrier 。
+	OmitFields bool
+
+	// KeepCommonFrames 为 true 时完整输出每一层的调用栈。
+	// 默认情况下，与上一个输出的调用栈末尾相同的部分被省略，以一行“... N frames in common with above”代替。
+	KeepCommonFrames bool
+
+	// FullFuncName 为 true 时输出函数的完整名称，如 github.com/user/pkg.Name ；否则输出短名称，如 pkg.Name 。
+	FullFuncName bool
+
+	// TrimGOROOT 为 true 时，去掉标准库文件路径中的 GOROOT 部分，如 /usr/local/go/src/runtime/proc.go -> runtime/proc.go 。
+	TrimGOROOT bool
+
+	// TrimGOPATH 为 true 时，去掉文件路径中的 GOPATH 部分，包括 $GOPATH/src/ 和模块缓存 $GOPATH/pkg/mod/ 。
+	TrimGOPATH bool
+
+	// TrimModuleRoot 为 true 时，去掉主模块（ main 包所在的模块）中文件路径的模块根目录部分，
+	// 如 /path/to/module/internal/a.go -> internal/a.go 。模块根目录根据 Frame 所在的包推断，不需要单独指定。
+	// 来自其他进程的 Frame （见 RemoteError ）不受影响。
+	TrimModuleRoot bool
+
+	// TrimPathPrefixes 指定一组需要从文件路径中去掉的前缀，如当前模块的根目录。
+	// 文件路径总是以“/”分隔，给定的前缀会被转换为相同的形式。
+	TrimPathPrefixes []string
+
+	// LayerPrefix 是内层错误的描述的前缀，默认为“=== ”。
+	LayerPrefix string
+
+	// StackPrefix 是调用栈的前缀，默认为“--- ”。
+	StackPrefix string
+
+	// FieldsPrefix 是附加在错误上的键值对的前缀，默认为“+++ ”。
+	FieldsPrefix string
+
+	// BranchIndent 是多个错误的分支（见 Describe() ）的缩进，默认为 4 个空格。
+	BranchIndent string
+}
+
+// NewPrinter 创建一个 Printer ，其设置与 Describe() 相同。
+func NewPrinter() *Printer {
+	return &Printer{
+		MaxDepth:     DefaultMaxDepth,
+		LayerPrefix:  "=== ",
+		StackPrefix:  "--- ",
+		FieldsPrefix: "+++ ",
+		BranchIndent: "    ",
+	}
+}
+
+// defaultPrinter 是 Describe() 使用的 Printer 。
+var defaultPrinter = NewPrinter()
+
+// Describe 返回一个字符串描述给定的错误。如果给定 nil ，返回空字符串。
+// 输出格式见 Describe() 函数。
+func (p *Printer) Describe(err error) string {
+	if err == nil {
+		return ""
+	}
+
+	var msg strings.Builder
+	p.describe(&msg, err, nil, 0, make(errorSet))
+	return msg.String()
+}
+
+// describe 逐层输出 err 及其内部错误。 above 是上一个输出的调用栈， depth 是 err 所在的层数，
+// visited 是当前路径上外层的错误，用于检测环。
+func (p *Printer) describe(msg *strings.Builder, err error, above []Frame, depth int, visited errorSet) {
+	// 离开时移除当前路径上的错误，以免同一个错误出现在多个分支时被误判为环。
+	var added []error
+	defer func() {
+		for _, e := range added {
+			visited.remove(e)
+		}
+	}()
+
+	start := msg.Len()
+	for ; err != nil; depth++ {
+		if msg.Len() > start {
+			msg.WriteString(p.LayerPrefix)
+		}
+
+		if chainDepthExceeded(depth, p.MaxDepth) {
+			msg.WriteString(truncatedMarker(err, visited))
+			msg.WriteRune('\n')
+			break
+		}
+
+		if !visited.add(err) {
+			msg.WriteString(cycleMarker)
+			msg.WriteRune('\n')
+			break
+		}
+		added = append(added, err)
+
+		var text, stack string
+
+		switch e := err.(type) {
+		case StackfulError:
+			text = e.ErrorWithoutStack() + "\n"
+
+			if p.OmitStack {
+				break
+			}
+
+			// 没有调用栈时，连同 StackPrefix 一起省略。
+			// 没有 Frame 时仍可能有文本形式的调用栈，如从 ErrorChain.RawStack 还原的 RemoteError 。
+			var frames []Frame
+			if st, ok := e.(StackTracer); ok {
+				frames = st.Frames()
+			}
+			if len(frames) == 0 {
+				frames = parseStack(e.Stack())
+			}
+
+			if len(frames) > 0 {
+				stack = p.StackPrefix + p.formatFrames(frames, above)
+				above = frames
+			} else if s := e.Stack(); s != "" {
+				stack = p.StackPrefix + p.limitLines(s)
+			}
+
+		default:
+			text = e.Error()
+
+			// 非 StackfulError 也可能带有调用栈，如 pkg/errors 创建的错误。
+			if !p.OmitStack {
+				if frames := foreignFrames(e); len(frames) > 0 {
+					stack = p.StackPrefix + p.formatFrames(frames, above)
+					above = frames
+				}
+			}
+		}
+
+		writeLine(msg, text)
+
+		if fc, ok := err.(FieldCarrier); ok && !p.OmitFields {
+			if fields := fc.Fields(); len(fields) > 0 {
+				msg.WriteString(p.FieldsPrefix)
+				msg.WriteString(formatFields(fields))
+				msg.WriteRune('\n')
+			}
+		}
+
+		writeLine(msg, stack)
+
+		// 一个类型不能同时有 Unwrap() error 和 Unwrap() []error ，有多个内部错误时，当前的链条到此为止。
+		if multi, ok := err.(interface{ Unwrap() []error }); ok {
+			p.describeBranches(msg, multi.Unwrap(), above, depth+1, visited)
+			break
+		}
+
+		err = errors.Unwrap(err)
+	}
+}
+
+// describeBranches 输出多个内部错误，每个错误作为一个分支，以“[序号/总数] ”开头，其余的行缩进。
+func (p *Printer) describeBranches(msg *strings.Builder, errs []error, above []Frame, depth int, visited errorSet) {
+	for i, e := range errs {
+		if e == nil {
+			continue
+		}
+
+		branch := new(strings.Builder)
+		p.describe(branch, e, above, depth, visited)
+
+		msg.WriteString(p.LayerPrefix)
+		msg.WriteRune('[')
+		msg.WriteString(strconv.Itoa(i + 1))
+		msg.WriteRune('/')
+		msg.WriteString(strconv.Itoa(len(errs)))
+		msg.WriteString("] ")
+		writeIndented(msg, branch.String(), p.BranchIndent)
+	}
+}
+
+// writeLine 输出 s ，若其不以换行结尾，则补充一个换行。 s 为空时不输出。
+func writeLine(b *strings.Builder, s string) {
+	if len(s) == 0 {
+		return
+	}
+
+	b.WriteString(s)
+	if s[len(s)-1] != '\n' {
+		b.WriteRune('\n')
+	}
+}
+
+// writeIndented 输出 s ，除第一行外的每一行都添加缩进。
+func writeIndented(b *strings.Builder, s, indent string) {
+	for {
+		idx := strings.IndexByte(s, '\n')
+		if idx < 0 || idx == len(s)-1 {
+			b.WriteString(s)
+			return
+		}
+
+		b.WriteString(s[:idx+1])
+		b.WriteString(indent)
+		s = s[idx+1:]
+	}
+}
+
+// formatFrames 输出一层错误的调用栈， above 是上一个输出的调用栈。
+func (p *Printer) formatFrames(frames, above []Frame) string {
+	common := 0
+	if !p.KeepCommonFrames {
+		common = commonFrames(frames, above)
+	}
+
+	frames = frames[:len(frames)-common]
+	more := 0
+	if p.MaxFrames > 0 && len(frames) > p.MaxFrames {
+		more = len(frames) - p.MaxFrames
+		frames = frames[:p.MaxFrames]
+	}
+
+	b := new(strings.Builder)
+	for _, f := range frames {
+		if f.Remote {
+			b.WriteString("(remote) ")
+		}
+		b.WriteRune('[')
+		b.WriteString(p.trimPath(f))
+		b.WriteRune(':')
+		b.WriteString(strconv.Itoa(f.Line))
+		b.WriteString("] ")
+		if p.FullFuncName {
+			b.WriteString(f.Function)
+		} else {
+			b.WriteString(f.ShortName())
+		}
+		b.WriteRune('\n')
+	}
+
+	if more > 0 {
+		b.WriteString("... ")
+		b.WriteString(strconv.Itoa(more))
+		b.WriteString(" more frames\n")
+	}
+
+	if common > 0 {
+		b.WriteString("... ")
+		b.WriteString(strconv.Itoa(common))
+		b.WriteString(" frames in common with above\n")
+	}
+
+	return b.String()
+}
+
+// limitLines 按 MaxFrames 限制无法解析为 Frame 的文本形式的调用栈的行数，超出的部分以一行“... N more frames”代替。
+func (p *Printer) limitLines(s string) string {
+	if p.MaxFrames <= 0 {
+		return s
+	}
+
+	lines := strings.SplitAfter(strings.TrimSuffix(s, "\n"), "\n")
+	if len(lines) <= p.MaxFrames {
+		return s
+	}
+
+	more := len(lines) - p.MaxFrames
+	return strings.Join(lines[:p.MaxFrames], "") + "... " + strconv.Itoa(more) + " more frames\n"
+}
+
+// parseStack 将 Stack() 输出的文本形式的调用栈解析为 Frame ，每一行的格式为“[(remote) ][file:line] func”。
+// 解析得到的 Frame 只有短的函数名称，没有 Package 和 PC 。若 s 为空或有任何一行不是此格式，返回 nil 。
+func parseStack(s string) []Frame {
+	const remotePrefix = "(remote) "
+
+	var frames []Frame
+	for _, line := range strings.Split(strings.TrimSuffix(s, "\n"), "\n") {
+		var f Frame
+		if strings.HasPrefix(line, remotePrefix) {
+			f.Remote = true
+			line = line[len(remotePrefix):]
+		}
+
+		end := strings.Index(line, "] ")
+		if !strings.HasPrefix(line, "[") || end < 0 {
+			return nil
+		}
+
+		fileLine := line[1:end]
+		colon := strings.LastIndexByte(fileLine, ':')
+		if colon < 0 {
+			return nil
+		}
+
+		n, err := strconv.Atoi(fileLine[colon+1:])
+		if err != nil {
+			return nil
+		}
+
+		f.File = fileLine[:colon]
+		f.Line = n
+		f.Function = line[end+2:]
+		frames = append(frames, f)
+	}
+	return frames
+}
+
+// trimPath 根据设置去掉 Frame 的文件路径的前缀。
+func (p *Printer) trimPath(f Frame) string {
+	file := f.File
+	for _, prefix := range p.TrimPathPrefixes {
+		if trimmed, ok := trimDirPrefix(file, filepath.ToSlash(prefix)); ok {
+			return trimmed
+		}
+	}
+
+	if p.TrimModuleRoot && !f.Remote {
+		if trimmed, ok := trimModuleRoot(file, f.Package); ok {
+			return trimmed
+		}
+	}
+
+	if p.TrimGOROOT {
+		if trimmed, ok := trimDirPrefix(file, goroot()+"/src"); ok {
+			return trimmed
+		}
+	}
+
+	if p.TrimGOPATH {
+		for _, gopath := range gopaths() {
+			if trimmed, ok := trimDirPrefix(file, gopath+"/pkg/mod"); ok {
+				return trimmed
+			}
+			if trimmed, ok := trimDirPrefix(file, gopath+"/src"); ok {
+				return trimmed
+			}
+		}
+	}
+
+	return file
+}
+
+// trimDirPrefix 若 file 位于目录 dir 下，则返回其相对路径。
+func trimDirPrefix(file, dir string) (string, bool) {
+	dir = strings.TrimSuffix(dir, "/")
+	if dir == "" || !strings.HasPrefix(file, dir+"/") {
+		return file, false
+	}
+	return file[len(dir)+1:], true
+}
+
+// trimModuleRoot 若包 pkg 属于主模块，则返回 file 相对于模块根目录的路径。
+// 包路径中模块路径之后的部分，即是包所在的目录相对于模块根目录的路径，据此去掉 file 中模块根目录的部分。
+func trimModuleRoot(file, pkg string) (string, bool) {
+	mod, mainPkg := mainModule()
+	if mod == "" {
+		return file, false
+	}
+
+	// main 包的函数名称中，包路径总是 main ，需替换为其实际的路径。
+	if pkg == "main" {
+		pkg = mainPkg
+	}
+
+	var rel string
+	switch {
+	case pkg == mod:
+		// 位于模块根目录。
+	case strings.HasPrefix(pkg, mod+"/"):
+		rel = pkg[len(mod)+1:]
+	default:
+		return file, false
+	}
+
+	if rel == "" {
+		return path.Base(file), true
+	}
+
+	if !strings.HasSuffix(path.Dir(file), "/"+rel) {
+		return file, false
+	}
+	return rel + "/" + path.Base(file), true
+}
+
+var (
+	mainModuleOnce sync.Once
+	mainModulePath string
+	mainPkgPath    string
+)
+
+// mainModule 返回主模块的路径和 main 包的路径。未使用模块编译时，返回空字符串。
+func mainModule() (string, string) {
+	mainModuleOnce.Do(func() {
+		bi, ok := debug.ReadBuildInfo()
+		if !ok {
+			return
+		}
+		mainModulePath = bi.Main.Path
+		mainPkgPath = bi.Path
+	})
+	return mainModulePath, mainPkgPath
+}
+
+var (
+	gorootOnce  sync.Once
+	gorootValue string
+)
+
+// goroot 返回编译时的 GOROOT ，即调用栈中标准库文件所在的根目录。
+// 它通过标准库中的一个函数所在的文件推断得到，使用 -trimpath 编译时为空字符串。
+func goroot() string {
+	gorootOnce.Do(func() {
+		// 此函数位于 $GOROOT/src/runtime 下。
+		f := runtime.FuncForPC(reflect.ValueOf(runtime.Callers).Pointer())
+		if f == nil {
+			return
+		}
+
+		file, _ := f.FileLine(f.Entry())
+		idx := strings.LastIndex(file, "/src/runtime/")
+		if idx > 0 {
+			gorootValue = file[:idx]
+		}
+	})
+	return gorootValue
+}
+
+// gopaths 返回 GOPATH 中的各个目录。未设置 GOPATH 时，返回默认的 $HOME/go 。
+func gopaths() []string {
+	gopath := os.Getenv("GOPATH")
+	if gopath == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return nil
+		}
+		gopath = filepath.Join(home, "go")
+	}
+
+	var res []string
+	for _, v := range filepath.SplitList(gopath) {
+		if v != "" {
+			res = append(res, filepath.ToSlash(v))
+		}
+	}
+	return res
+}
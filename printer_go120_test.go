@@ -0,0 +1,19 @@
+//go:build go1.20
+
+package errx
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDescribe_join(t *testing.T) {
+	// BizError.Error() 不含调用栈，便于断言 errors.Join() 得到的错误的描述。
+	err := Wrap("outer", errors.Join(NewBizError(1, "a", nil), NewBizError(1, "b", errors.New("c"))))
+	res := Describe(err)
+	require.Regexp(t, `^outer: \(1\) a\n\(1\) b\n--- \[.+printer_go120_test\.go:\d+\] go-errx\.TestDescribe_join\n`, res)
+	require.Regexp(t, `\n=== \(1\) a\n\(1\) b\n=== \[1/2\] \(1\) a\n    --- \[.+printer_go120_test\.go:\d+\] go-errx\.TestDescribe_join\n    \.\.\. \d+ frames in common with above\n`, res)
+	require.Regexp(t, `\n=== \[2/2\] \(1\) b\n    --- \[.+printer_go120_test\.go:\d+\] go-errx\.TestDescribe_join\n    \.\.\. \d+ frames in common with above\n    === c\n$`, res)
+}
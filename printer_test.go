@@ -0,0 +1,301 @@
+package errx
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPrinter_Describe(t *testing.T) {
+	inner := func() error {
+		return Wrap("inner", errors.New("gg"))
+	}
+	cause := inner()
+	err := Wrap("outer", cause)
+
+	t.Run("default", func(t *testing.T) {
+		require.Equal(t, Describe(err), NewPrinter().Describe(err))
+		require.Equal(t, "", NewPrinter().Describe(nil))
+	})
+
+	t.Run("max-depth", func(t *testing.T) {
+		p := NewPrinter()
+		p.MaxDepth = 2
+		res := p.Describe(err)
+		require.Regexp(t, `^outer: inner: gg\n--- `, res)
+		require.Regexp(t, `=== inner: gg\n--- `, res)
+		require.NotContains(t, res, "=== gg")
+		require.True(t, strings.HasSuffix(res, "\n=== <truncated 1 more>\n"))
+	})
+
+	t.Run("max-frames", func(t *testing.T) {
+		p := NewPrinter()
+		p.MaxFrames = 1
+		p.KeepCommonFrames = true
+		res := p.Describe(err)
+		require.Regexp(t, `^outer: inner: gg\n--- \[.+printer_test\.go:\d+\] go-errx\.TestPrinter_Describe\n\.\.\. \d+ more frames\n=== `, res)
+		require.Regexp(t, `=== inner: gg\n--- \[.+printer_test\.go:\d+\] go-errx\.TestPrinter_Describe\.func1\n\.\.\. \d+ more frames\n=== gg\n$`, res)
+	})
+
+	t.Run("max-frames-and-common", func(t *testing.T) {
+		p := NewPrinter()
+		p.MaxFrames = 1
+		res := p.Describe(err)
+		require.Regexp(t, `=== inner: gg\n--- \[.+\] go-errx\.TestPrinter_Describe\.func1\n`+
+			`\.\.\. 1 more frames\n\.\.\. \d+ frames in common with above\n=== gg\n$`, res)
+	})
+
+	t.Run("keep-common-frames", func(t *testing.T) {
+		p := NewPrinter()
+		p.KeepCommonFrames = true
+		res := p.Describe(err)
+		require.NotContains(t, res, "in common with above")
+		require.Regexp(t, `=== inner: gg\n--- \[.+\] go-errx\.TestPrinter_Describe\.func1\n\[.+\] go-errx\.TestPrinter_Describe\n\[.+\] testing\.tRunner`, res)
+	})
+
+	t.Run("omit-stack", func(t *testing.T) {
+		p := NewPrinter()
+		p.OmitStack = true
+		require.Equal(t, "outer: inner: gg\n=== inner: gg\n=== gg\n", p.Describe(err))
+	})
+
+	t.Run("prefixes", func(t *testing.T) {
+		p := NewPrinter()
+		p.OmitStack = true
+		p.LayerPrefix = "caused by: "
+		require.Equal(t, "outer: inner: gg\ncaused by: inner: gg\ncaused by: gg\n", p.Describe(err))
+
+		p = NewPrinter()
+		p.StackPrefix = "  at "
+		require.Regexp(t, `^outer: inner: gg\n  at \[.+printer_test\.go`, p.Describe(err))
+	})
+
+	t.Run("full-func-name", func(t *testing.T) {
+		p := NewPrinter()
+		p.FullFuncName = true
+		require.Regexp(t, `^outer: inner: gg\n--- \[.+printer_test\.go:\d+\] github\.com/cmstar/go-errx\.TestPrinter_Describe\n`, p.Describe(err))
+	})
+
+	t.Run("trim-prefixes", func(t *testing.T) {
+		wd, e := os.Getwd()
+		require.NoError(t, e)
+
+		p := NewPrinter()
+		p.TrimPathPrefixes = []string{"/not/exist", wd + string(filepath.Separator)}
+		require.Regexp(t, `^outer: inner: gg\n--- \[printer_test\.go:\d+\] go-errx\.TestPrinter_Describe\n`, p.Describe(err))
+	})
+
+	t.Run("trim-module-root", func(t *testing.T) {
+		p := NewPrinter()
+		p.TrimModuleRoot = true
+		res := p.Describe(err)
+		require.Regexp(t, `^outer: inner: gg\n--- \[printer_test\.go:\d+\] go-errx\.TestPrinter_Describe\n`, res)
+		require.Regexp(t, `\n\[.+/testing\.go:\d+\] testing\.tRunner\n`, res)
+	})
+
+	t.Run("trim-goroot", func(t *testing.T) {
+		p := NewPrinter()
+		p.TrimGOROOT = true
+		require.Regexp(t, `\n\[testing/testing\.go:\d+\] testing\.tRunner\n`, p.Describe(err))
+	})
+}
+
+func TestPrinter_trimPath(t *testing.T) {
+	t.Setenv("GOPATH", "/gp1"+string(filepath.ListSeparator)+"/gp2")
+
+	p := &Printer{
+		TrimGOROOT:       true,
+		TrimGOPATH:       true,
+		TrimPathPrefixes: []string{"/work/proj/"},
+	}
+
+	cases := map[string]string{
+		"":                                 "",
+		"/work/proj/a.go":                  "a.go",
+		"/work/proj2/a.go":                 "/work/proj2/a.go",
+		"/gp1/src/x/a.go":                  "x/a.go",
+		"/gp2/pkg/mod/x@v1.0.0/a.go":       "x@v1.0.0/a.go",
+		"/gp3/pkg/mod/x@v1.0.0/a.go":       "/gp3/pkg/mod/x@v1.0.0/a.go",
+		goroot() + "/src/runtime/proc.go":  "runtime/proc.go",
+		goroot() + "/misc/runtime/proc.go": goroot() + "/misc/runtime/proc.go",
+	}
+	for file, want := range cases {
+		require.Equal(t, want, p.trimPath(Frame{File: file}), file)
+	}
+
+	// 不开启的选项不生效。
+	p = &Printer{}
+	require.Equal(t, "/gp1/src/x/a.go", p.trimPath(Frame{File: "/gp1/src/x/a.go"}))
+}
+
+func TestPrinter_trimModuleRoot(t *testing.T) {
+	mod, _ := mainModule()
+	require.Equal(t, "github.com/cmstar/go-errx", mod)
+
+	p := &Printer{TrimModuleRoot: true}
+	cases := []struct {
+		frame Frame
+		want  string
+	}{
+		{Frame{Package: mod, File: "/work/errx/a.go"}, "a.go"},
+		{Frame{Package: mod + "/httpx", File: "/work/errx/httpx/a.go"}, "httpx/a.go"},
+		{Frame{Package: mod + "/internal/x", File: "/work/errx/internal/x/a.go"}, "internal/x/a.go"},
+
+		// 目录与包路径不符时，无法推断模块根目录。
+		{Frame{Package: mod + "/httpx", File: "/work/errx/other/a.go"}, "/work/errx/other/a.go"},
+
+		// 其他模块和来自其他进程的 Frame 不受影响。
+		{Frame{Package: mod + "x", File: "/work/errxx/a.go"}, "/work/errxx/a.go"},
+		{Frame{Package: "testing", File: "/go/src/testing/testing.go"}, "/go/src/testing/testing.go"},
+		{Frame{Package: mod, File: "/work/errx/a.go", Remote: true}, "/work/errx/a.go"},
+	}
+	for _, c := range cases {
+		require.Equal(t, c.want, p.trimPath(c.frame), c.frame.File)
+	}
+}
+
+func TestPrinter_textStack(t *testing.T) {
+	text := "[/work/a.go:1] pkg.a\n[/work/b.go:2] pkg.b\n[/work/c.go:3] pkg.c\n"
+
+	t.Run("parse", func(t *testing.T) {
+		require.Equal(t, []Frame{
+			{Function: "pkg.a", File: "/work/a.go", Line: 1},
+			{Function: "pkg.b", File: "/work/b.go", Line: 2, Remote: true},
+		}, parseStack("[/work/a.go:1] pkg.a\n(remote) [/work/b.go:2] pkg.b\n"))
+
+		require.Nil(t, parseStack(""))
+		require.Nil(t, parseStack("stack"))
+		require.Nil(t, parseStack("[/work/a.go:1] pkg.a\n\n[/work/b.go:2] pkg.b\n"))
+		require.Nil(t, parseStack("[/work/a.go:x] pkg.a\n"))
+	})
+
+	t.Run("stackful", func(t *testing.T) {
+		// 非 StackTracer 的 StackfulError ，其调用栈同样受 MaxFrames 和 TrimPathPrefixes 影响。
+		p := NewPrinter()
+		p.MaxFrames = 2
+		p.TrimPathPrefixes = []string{"/work"}
+		require.Equal(t, "m\n--- [a.go:1] pkg.a\n[b.go:2] pkg.b\n... 1 more frames\n", p.Describe(textStackError(text)))
+	})
+
+	t.Run("remote", func(t *testing.T) {
+		chain := ErrorChain{{Message: "m", RawStack: text}}
+		p := NewPrinter()
+		p.MaxFrames = 1
+		require.Equal(t, "m\n--- (remote) [/work/a.go:1] pkg.a\n... 2 more frames\n", p.Describe(chain.Err()))
+	})
+
+	t.Run("unparsed", func(t *testing.T) {
+		// 无法解析时仅按行数限制。
+		p := NewPrinter()
+		p.MaxFrames = 1
+		require.Equal(t, "m\n--- line1\n... 1 more frames\n", p.Describe(textStackError("line1\nline2\n")))
+		require.Equal(t, "m\n--- line1\n", p.Describe(textStackError("line1")))
+	})
+}
+
+// textStackError 是只有文本形式的调用栈的 StackfulError 。
+type textStackError string
+
+func (e textStackError) Error() string             { return "m" }
+func (e textStackError) ErrorWithoutStack() string { return "m" }
+func (e textStackError) Cause() error              { return nil }
+func (e textStackError) Stack() string             { return string(e) }
+
+func TestGoroot(t *testing.T) {
+	root := goroot()
+	require.NotEmpty(t, root)
+	require.False(t, strings.HasSuffix(root, "/"))
+
+	// 标准库的调用栈位于 GOROOT 下。
+	frames := GetErrorStack(1).Frames()
+	last := frames[len(frames)-1]
+	require.Regexp(t, "^"+regexp.QuoteMeta(root)+"/src/testing/", last.File)
+}
+
+// multiError 实现 Unwrap() []error ，用于模拟 errors.Join() 得到的错误。
+type multiError []error
+
+func (m multiError) Error() string {
+	var b strings.Builder
+	for _, e := range m {
+		if e == nil {
+			continue
+		}
+		if b.Len() > 0 {
+			b.WriteRune('\n')
+		}
+		if se, ok := e.(StackfulError); ok {
+			b.WriteString(se.ErrorWithoutStack())
+		} else {
+			b.WriteString(e.Error())
+		}
+	}
+	return b.String()
+}
+
+func (m multiError) Unwrap() []error {
+	return m
+}
+
+func TestPrinter_Describe_branches(t *testing.T) {
+	p := NewPrinter()
+	p.OmitStack = true
+
+	t.Run("flat", func(t *testing.T) {
+		err := multiError{WrapWithoutStack("a", nil), nil, WrapWithoutStack("b", nil)}
+		require.Equal(t, "a\nb\n=== [1/3] a\n=== [3/3] b\n", p.Describe(err))
+	})
+
+	t.Run("nested", func(t *testing.T) {
+		err := Wrap("outer", multiError{
+			Wrap("a", errors.New("a1")),
+			multiError{WrapWithoutStack("b1", nil), WrapWithoutStack("b2", errors.New("b3"))},
+		})
+		require.Equal(t, ""+
+			"outer: a: a1\nb1\nb2: b3\n"+
+			"=== a: a1\nb1\nb2: b3\n"+
+			"=== [1/2] a: a1\n"+
+			"    === a1\n"+
+			"=== [2/2] b1\n"+
+			"    b2: b3\n"+
+			"    === [1/2] b1\n"+
+			"    === [2/2] b2: b3\n"+
+			"        === b3\n",
+			p.Describe(err))
+	})
+
+	t.Run("max-depth", func(t *testing.T) {
+		p := NewPrinter()
+		p.OmitStack = true
+		p.MaxDepth = 2
+
+		err := Wrap("outer", multiError{Wrap("a", errors.New("a1"))})
+		require.Equal(t, "outer: a: a1\n=== a: a1\n=== [1/1] <truncated 2 more>\n", p.Describe(err))
+
+		p.MaxDepth = 3
+		require.Equal(t, "outer: a: a1\n=== a: a1\n=== [1/1] a: a1\n    === <truncated 1 more>\n", p.Describe(err))
+	})
+
+	t.Run("indent", func(t *testing.T) {
+		p := NewPrinter()
+		p.OmitStack = true
+		p.BranchIndent = "\t"
+		err := multiError{Wrap("a", errors.New("a1"))}
+		require.Equal(t, "a: a1\n=== [1/1] a: a1\n\t=== a1\n", p.Describe(err))
+	})
+
+	t.Run("stack", func(t *testing.T) {
+		err := Wrap("outer", multiError{
+			Wrap("a", nil),
+			WrapWithoutStack("b", nil),
+		})
+		res := Describe(err)
+		require.Regexp(t, `^outer: a\nb\n--- \[.+printer_test\.go:\d+\] go-errx\.TestPrinter_Describe_branches\.func5\n`, res)
+		require.Regexp(t, `\n=== a\nb\n=== \[1/2\] a\n    --- \[.+printer_test\.go:\d+\] go-errx\.TestPrinter_Describe_branches\.func5\n    \.\.\. \d+ frames in common with above\n=== \[2/2\] b\n$`, res)
+	})
+}
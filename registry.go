@@ -0,0 +1,99 @@
+package errx
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// ErrDuplicateCode 表示向 Registry 登记了重复的错误码。 Registry.Register() 返回的错误可通过 errors.Is() 与其比较。
+var ErrDuplicateCode = errors.New("duplicate BizError code")
+
+// CodeDef 是一个 BizError 错误码的定义。
+type CodeDef struct {
+	// Code 是错误码。
+	Code int
+
+	// Message 是默认的错误描述，即创建 BizError 时通常使用的 message 。
+	Message string
+
+	// Category 是错误的类别，如 validation 、 auth 等，用于对错误码分组。
+	Category string
+
+	// Description 是错误的详细说明，如产生的原因和处理方式，通常用于生成文档。
+	Description string
+}
+
+// Registry 用于集中登记 BizError 的错误码及其定义，以避免不同的地方使用了重复的错误码。
+// 可在运行时查询错误码的定义，也可列出所有的错误码以生成文档。
+// Registry 是并发安全的，零值不可用，需通过 NewRegistry() 创建。
+type Registry struct {
+	mu   sync.RWMutex
+	defs map[int]CodeDef
+}
+
+// DefaultRegistry 是默认的 Registry ，适用于整个程序共用一套错误码的场景。
+var DefaultRegistry = NewRegistry()
+
+// NewRegistry 创建一个空的 Registry 。
+func NewRegistry() *Registry {
+	return &Registry{
+		defs: make(map[int]CodeDef),
+	}
+}
+
+// Register 登记给定的错误码定义。若其中有已登记过的错误码（或给定的定义之间有重复），返回错误，
+// 其可通过 errors.Is(err, ErrDuplicateCode) 判断。出错时，给定的定义都不会被登记。
+func (r *Registry) Register(defs ...CodeDef) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	seen := make(map[int]CodeDef, len(defs))
+	for _, def := range defs {
+		if old, ok := r.defs[def.Code]; ok {
+			return fmt.Errorf("%w: %d, registered as %q", ErrDuplicateCode, def.Code, old.Message)
+		}
+		if old, ok := seen[def.Code]; ok {
+			return fmt.Errorf("%w: %d, registered as %q", ErrDuplicateCode, def.Code, old.Message)
+		}
+		seen[def.Code] = def
+	}
+
+	for _, def := range defs {
+		r.defs[def.Code] = def
+	}
+	return nil
+}
+
+// MustRegister 与 Register() 相同，但在出错时 panic 。适合在包的 init() 中使用，使重复的错误码在程序启动时即被发现。
+func (r *Registry) MustRegister(defs ...CodeDef) {
+	if err := r.Register(defs...); err != nil {
+		panic(err)
+	}
+}
+
+// Lookup 返回给定错误码的定义。若错误码未登记，第二个返回值为 false 。
+func (r *Registry) Lookup(code int) (CodeDef, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	def, ok := r.defs[code]
+	return def, ok
+}
+
+// All 返回所有已登记的错误码定义，按错误码升序排列。
+func (r *Registry) All() []CodeDef {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	res := make([]CodeDef, 0, len(r.defs))
+	for _, def := range r.defs {
+		res = append(res, def)
+	}
+
+	sort.Slice(res, func(i, j int) bool {
+		return res[i].Code < res[j].Code
+	})
+	return res
+}
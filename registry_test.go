@@ -0,0 +1,53 @@
+package errx
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRegistry(t *testing.T) {
+	r := NewRegistry()
+	require.Empty(t, r.All())
+
+	d1 := CodeDef{Code: 2, Message: "m2", Category: "c", Description: "d2"}
+	d2 := CodeDef{Code: 1, Message: "m1"}
+	require.NoError(t, r.Register(d1, d2))
+
+	def, ok := r.Lookup(2)
+	require.True(t, ok)
+	require.Equal(t, d1, def)
+
+	_, ok = r.Lookup(3)
+	require.False(t, ok)
+
+	require.Equal(t, []CodeDef{d2, d1}, r.All())
+
+	t.Run("duplicate", func(t *testing.T) {
+		err := r.Register(CodeDef{Code: 3}, CodeDef{Code: 1, Message: "x"})
+		require.True(t, errors.Is(err, ErrDuplicateCode))
+		require.Equal(t, `duplicate BizError code: 1, registered as "m1"`, err.Error())
+
+		// 出错时不登记任何定义。
+		_, ok := r.Lookup(3)
+		require.False(t, ok)
+	})
+
+	t.Run("duplicate-in-args", func(t *testing.T) {
+		err := r.Register(CodeDef{Code: 4, Message: "a"}, CodeDef{Code: 4})
+		require.True(t, errors.Is(err, ErrDuplicateCode))
+
+		_, ok := r.Lookup(4)
+		require.False(t, ok)
+	})
+
+	t.Run("must", func(t *testing.T) {
+		r.MustRegister(CodeDef{Code: 5})
+		require.Len(t, r.All(), 3)
+
+		require.Panics(t, func() {
+			r.MustRegister(CodeDef{Code: 5})
+		})
+	})
+}
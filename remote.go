@@ -0,0 +1,273 @@
+package errx
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// RemoteError 是从 ErrorChain 还原的错误，通常来自另一个进程，如通过 HTTP 调用的其他服务。
+// 它实现 StackfulError ，其调用栈的各个 Frame 的 Remote 字段为 true 。
+//
+// 原本是 BizError 的错误被还原为 RemoteBizError ，可以像本地的 BizError 一样通过 Code() 区分。
+type RemoteError struct {
+	ErrorCause
+	ErrorStack
+	ErrorFields
+	msg      string
+	typ      string
+	rawStack string
+}
+
+var _ StackfulError = (*RemoteError)(nil)
+var _ StackTracer = (*RemoteError)(nil)
+var _ FieldCarrier = (*RemoteError)(nil)
+var _ FieldAttacher = (*RemoteError)(nil)
+var _ fmt.Formatter = (*RemoteError)(nil)
+
+// Error 返回以 Describe() 的格式输出错误信息。
+func (e *RemoteError) Error() string {
+	return Describe(e)
+}
+
+// ErrorWithoutStack 实现 StackfulError.ErrorWithoutStack() 。返回原错误的 ErrorWithoutStack() 或 Error() 。
+func (e *RemoteError) ErrorWithoutStack() string {
+	return e.msg
+}
+
+// Stack 实现 StackfulError.Stack() 。
+// 若还原自 ChainLayer.RawStack ，返回其文本，与 Frame 一样，每一行带有前缀“(remote) ”。
+func (e *RemoteError) Stack() string {
+	if e.rawStack != "" {
+		return markRemote(e.rawStack)
+	}
+	return e.ErrorStack.Stack()
+}
+
+// hasStack 返回是否有调用栈。还原自 ChainLayer.RawStack 时，虽然没有 Frame ，也视为有调用栈。
+func (e *RemoteError) hasStack() bool {
+	return e.rawStack != "" || e.ErrorStack.hasStack()
+}
+
+// markRemote 为文本形式的调用栈的每个非空行添加前缀“(remote) ”，已有此前缀的行（如经过多次传递）不再重复添加。
+func markRemote(s string) string {
+	const prefix = "(remote) "
+
+	var b strings.Builder
+	for len(s) > 0 {
+		line := s
+		if idx := strings.IndexByte(s, '\n'); idx >= 0 {
+			line = s[:idx+1]
+		}
+		s = s[len(line):]
+
+		if line != "\n" && !strings.HasPrefix(line, prefix) {
+			b.WriteString(prefix)
+		}
+		b.WriteString(line)
+	}
+	return b.String()
+}
+
+// With 实现 FieldAttacher.With() 。
+func (e *RemoteError) With(key string, value interface{}) StackfulError {
+	res := *e
+	res.ErrorFields = e.ErrorFields.with(Field{key, value})
+	return &res
+}
+
+// Format 实现 fmt.Formatter ，规则与 ErrorWrapper.Format() 相同。
+func (e *RemoteError) Format(f fmt.State, verb rune) {
+	formatStackful(f, verb, e)
+}
+
+// Type 返回原错误的 Go 类型，如 *errx.ErrorWrapper 。
+func (e *RemoteError) Type() string {
+	return e.typ
+}
+
+// RemoteBizError 是从 ErrorChain 还原的 BizError ，见 RemoteError 。
+type RemoteBizError struct {
+	RemoteError
+	code    int
+	message string
+}
+
+var _ BizError = (*RemoteBizError)(nil)
+var _ BizFieldAttacher = (*RemoteBizError)(nil)
+
+// Code 返回错误码。
+func (e *RemoteBizError) Code() int {
+	return e.code
+}
+
+// Message 返回错误的描述信息，不含错误码。
+func (e *RemoteBizError) Message() string {
+	return e.message
+}
+
+// With 实现 BizFieldAttacher.With() ，返回的仍是 RemoteBizError 。
+func (e *RemoteBizError) With(key string, value interface{}) BizError {
+	res := *e
+	res.ErrorFields = e.ErrorFields.with(Field{key, value})
+	return &res
+}
+
+// Format 实现 fmt.Formatter ，规则与 BizError 相同。
+func (e *RemoteBizError) Format(f fmt.State, verb rune) {
+	formatStackful(f, verb, e)
+}
+
+// Is 用于支持 errors.Is() ，与 BizError 一样按错误码匹配。
+func (e *RemoteBizError) Is(target error) bool {
+	return isBizCode(e.code, target)
+}
+
+// RemoteMulti 是从 ErrorChain 还原的有多个内部错误的错误，如 Multi 和 errors.Join() 的结果，见 RemoteError 。
+// 它实现 Unwrap() []error ，各个内部错误同样被还原为 RemoteError 等，保留各自的调用栈和错误码。
+// 它本身不实现 BizError ，这样的一层原本若是 BizError ，其错误码仅保留在 ChainLayer 中。
+type RemoteMulti struct {
+	RemoteError
+	errs []error
+}
+
+var _ StackfulError = (*RemoteMulti)(nil)
+var _ StackTracer = (*RemoteMulti)(nil)
+var _ FieldCarrier = (*RemoteMulti)(nil)
+var _ FieldAttacher = (*RemoteMulti)(nil)
+var _ fmt.Formatter = (*RemoteMulti)(nil)
+
+// Error 返回以 Describe() 的格式输出错误信息，每个内部错误作为一个分支展示。
+func (e *RemoteMulti) Error() string {
+	return Describe(e)
+}
+
+// Errors 返回所有的内部错误。返回的是一个副本，对其修改不影响当前实例。
+func (e *RemoteMulti) Errors() []error {
+	if len(e.errs) == 0 {
+		return nil
+	}
+
+	res := make([]error, len(e.errs))
+	copy(res, e.errs)
+	return res
+}
+
+// Unwrap 返回所有的内部错误，以支持 errors.Is() 和 errors.As() （ Go 1.20 及以上）。返回值不应被修改。
+func (e *RemoteMulti) Unwrap() []error {
+	return e.errs
+}
+
+// Cause 实现 StackfulError.Cause() 。与 Multi 相同，总是返回 nil ，应使用 Errors() 。
+func (e *RemoteMulti) Cause() error {
+	return nil
+}
+
+// With 实现 FieldAttacher.With() ，返回的仍是 RemoteMulti 。
+func (e *RemoteMulti) With(key string, value interface{}) StackfulError {
+	res := *e
+	res.ErrorFields = e.ErrorFields.with(Field{key, value})
+	return &res
+}
+
+// Format 实现 fmt.Formatter ，规则与 Multi 相同。
+func (e *RemoteMulti) Format(f fmt.State, verb rune) {
+	formatStackful(f, verb, e)
+}
+
+// ErrorWithoutStack 实现 StackfulError.ErrorWithoutStack() 。
+func (e *RemoteBizError) ErrorWithoutStack() string {
+	return e.Error()
+}
+
+// Error 实现 error 接口，与 BizError 相同，格式为： (Code) Message 。
+func (e *RemoteBizError) Error() string {
+	var b strings.Builder
+	b.WriteRune('(')
+	b.WriteString(strconv.Itoa(e.code))
+	b.WriteString(") ")
+	b.WriteString(e.message)
+	return b.String()
+}
+
+// UnmarshalChain 从 MarshalChain() 输出的 JSON 中还原 ErrorChain ，可再通过 ErrorChain.Err() 得到对应的错误。
+func UnmarshalChain(data []byte) (ErrorChain, error) {
+	var chain ErrorChain
+	err := json.Unmarshal(data, &chain)
+	if err != nil {
+		return nil, err
+	}
+	return chain, nil
+}
+
+// Err 将 ErrorChain 还原为 RemoteError 或 RemoteBizError 组成的错误链，返回最外层的错误。
+// 若 ErrorChain 为空，返回 nil 。
+//
+// 还原的错误保留了原错误的描述、类型、 BizError 的错误码、键值对和调用栈，调用栈的各个 Frame 被标记为 Remote 。
+// 记录了 ChainLayer.Branches 的一层被还原为 RemoteMulti 。
+func (c ErrorChain) Err() error {
+	var cause error
+	for i := len(c) - 1; i >= 0; i-- {
+		cause = c[i].remoteError(cause)
+	}
+	return cause
+}
+
+func (l ChainLayer) remoteError(cause error) error {
+	var frames []Frame
+	if len(l.Stack) > 0 {
+		frames = make([]Frame, len(l.Stack))
+		for i, f := range l.Stack {
+			f.Remote = true
+			f.PC = 0
+			frames[i] = f
+		}
+	}
+
+	// JSON 对象没有顺序，按键排序，以便输出稳定。
+	var fields []Field
+	if len(l.Fields) > 0 {
+		keys := make([]string, 0, len(l.Fields))
+		for k := range l.Fields {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+
+		fields = make([]Field, len(keys))
+		for i, k := range keys {
+			fields[i] = Field{k, l.Fields[k]}
+		}
+	}
+
+	remote := RemoteError{
+		ErrorCause:  ErrorCause{cause},
+		ErrorStack:  newResolvedStack(frames),
+		ErrorFields: ErrorFields{fields},
+		msg:         l.Message,
+		typ:         l.Type,
+		rawStack:    l.RawStack,
+	}
+
+	if len(l.Branches) > 0 {
+		errs := make([]error, 0, len(l.Branches))
+		for _, b := range l.Branches {
+			if e := b.Err(); e != nil {
+				errs = append(errs, e)
+			}
+		}
+		remote.ErrorCause = ErrorCause{}
+		return &RemoteMulti{RemoteError: remote, errs: errs}
+	}
+
+	if l.Code == nil {
+		return &remote
+	}
+
+	return &RemoteBizError{
+		RemoteError: remote,
+		code:        *l.Code,
+		message:     l.BizMessage,
+	}
+}
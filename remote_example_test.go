@@ -0,0 +1,34 @@
+package errx_test
+
+import (
+	"fmt"
+
+	"github.com/cmstar/go-errx"
+)
+
+func ExampleErrorChain_Err() {
+	// 当前示例演示如何在服务之间传递错误链，并在调用方还原 BizError 。
+
+	// 服务端：将错误序列化后返回给调用方。
+	data, _ := errx.MarshalChain(errx.Wrap("from server", errx.NewBizError(2, "hello", nil)))
+
+	// 调用方：还原错误链。
+	chain, _ := errx.UnmarshalChain(data)
+	err := chain.Err()
+	fmt.Println(err.(errx.StackfulError).ErrorWithoutStack())
+
+	// 可以像本地的 BizError 一样通过 Code() 区分。
+	biz, ok := err.(errx.StackfulError).Cause().(errx.BizError)
+	if ok {
+		switch biz.Code() {
+		case 1:
+			fmt.Println("BizError1: " + biz.Error())
+		case 2:
+			fmt.Println("BizError2: " + biz.Error())
+		}
+	}
+
+	// Output:
+	// from server: (2) hello
+	// BizError2: (2) hello
+}
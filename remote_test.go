@@ -0,0 +1,168 @@
+package errx
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestErrorChain_Err(t *testing.T) {
+	t.Run("empty", func(t *testing.T) {
+		require.Nil(t, ErrorChain(nil).Err())
+		require.Nil(t, ErrorChain{}.Err())
+	})
+
+	t.Run("round-trip", func(t *testing.T) {
+		origin := Wrap("p1", fmt.Errorf("p2: %w", NewBizError(12, "biz", errors.New("gg"))))
+		data, err := MarshalChain(origin)
+		require.NoError(t, err)
+
+		chain, err := UnmarshalChain(data)
+		require.NoError(t, err)
+		require.Len(t, chain, 4)
+
+		got := chain.Err()
+		a := require.New(t)
+
+		// 第 1 层。
+		w, ok := got.(*RemoteError)
+		a.True(ok)
+		a.Equal("p1: p2: (12) biz", w.ErrorWithoutStack())
+		a.Equal("*errx.ErrorWrapper", w.Type())
+		a.Regexp(`^\(remote\) \[.+remote_test\.go:\d+\] go-errx\.TestErrorChain_Err\.func2\n`, w.Stack())
+		a.Regexp(`^p1: p2: \(12\) biz\n--- \(remote\) \[.+remote_test\.go:\d+\] go-errx\.TestErrorChain_Err\.func2\n`, w.Error())
+
+		frames := w.Frames()
+		a.NotEmpty(frames)
+		a.True(frames[0].Remote)
+		a.Equal("github.com/cmstar/go-errx", frames[0].Package)
+		a.Zero(frames[0].PC)
+
+		// 第 2 层。
+		w, ok = w.Cause().(*RemoteError)
+		a.True(ok)
+		a.Equal("p2: (12) biz", w.ErrorWithoutStack())
+		a.Equal("*fmt.wrapError", w.Type())
+		a.Equal("", w.Stack())
+		a.Nil(w.Frames())
+
+		// 第 3 层。
+		var biz BizError
+		a.True(errors.As(got, &biz))
+		a.Equal(12, biz.Code())
+		a.Equal("biz", biz.Message())
+		a.Equal("(12) biz", biz.Error())
+		a.Equal("(12) biz", biz.ErrorWithoutStack())
+		a.Equal("*errx.bizErr", biz.(*RemoteBizError).Type())
+		a.Regexp(`^\(remote\) \[.+remote_test\.go:\d+\] go-errx\.TestErrorChain_Err\.func2\n`, biz.Stack())
+
+		// 第 4 层。
+		inner := biz.Cause()
+		a.Equal("gg", inner.(StackfulError).ErrorWithoutStack())
+		a.Nil(errors.Unwrap(inner))
+
+		// Describe 与原错误的输出一致，仅多了 remote 标记。
+		a.Regexp(`^p1: p2: \(12\) biz\n--- \(remote\) \[.+\n(.+\n)*=== p2: \(12\) biz\n=== \(12\) biz\n--- \(remote\) \[.+\n(.+\n)*=== gg\n$`, Describe(got))
+	})
+
+	t.Run("branches", func(t *testing.T) {
+		origin := NewMulti("m").
+			Append(NewBizError(2, "b", nil)).
+			Append(Wrap("w", errors.New("gg")))
+		data, err := json.Marshal(Wrap("outer", origin))
+		require.NoError(t, err)
+
+		chain, err := UnmarshalChain(data)
+		require.NoError(t, err)
+
+		got := chain.Err()
+		code, ok := CodeOf(got)
+		require.True(t, ok)
+		require.Equal(t, 2, code)
+		require.True(t, HasCode(got, 2))
+
+		m, ok := errors.Unwrap(got).(*RemoteMulti)
+		require.True(t, ok)
+		require.Nil(t, m.Cause())
+		require.Equal(t, "*errx.Multi", m.Type())
+		require.Equal(t, "m: (2) b; w: gg", m.ErrorWithoutStack())
+		require.NotEmpty(t, m.Frames())
+
+		errs := m.Errors()
+		require.Len(t, errs, 2)
+		require.Equal(t, errs, m.Unwrap())
+
+		biz := errs[0].(*RemoteBizError)
+		require.Equal(t, 2, biz.Code())
+		require.True(t, biz.Frames()[0].Remote)
+
+		w := errs[1].(*RemoteError)
+		require.Equal(t, "w: gg", w.ErrorWithoutStack())
+		require.True(t, w.Frames()[0].Remote)
+		require.Equal(t, "gg", w.Cause().(StackfulError).ErrorWithoutStack())
+
+		// 与原错误的输出一致，仅多了 remote 标记。
+		require.Regexp(t, `\n=== \[1/2\] \(2\) b\n    --- \(remote\) \[.+\n(.+\n)*=== \[2/2\] w: gg\n    --- \(remote\) \[.+\n(.+\n)*    === gg\n$`, Describe(got))
+		require.Equal(t, "m: (2) b; w: gg", fmt.Sprintf("%s", m))
+
+		// 可再次传递。
+		require.Len(t, NewErrorChain(got)[1].Branches, 2)
+	})
+
+	t.Run("raw-stack", func(t *testing.T) {
+		got := ErrorChain{{Message: "raw", Type: "x", RawStack: "stack"}}.Err().(*RemoteError)
+		require.Equal(t, "(remote) stack", got.Stack())
+		require.Nil(t, got.Frames())
+
+		// 没有 Frame 时，输出文本形式的调用栈，同样带有 remote 标记。
+		got = ErrorChain{{Message: "m", RawStack: "RAW\n\nL2\n"}}.Err().(*RemoteError)
+		require.Equal(t, "m\n--- (remote) RAW\n\n(remote) L2\n", Describe(got))
+
+		// 再次传递时保留文本形式的调用栈，不重复添加标记。
+		again := NewErrorChain(got)
+		require.Equal(t, "(remote) RAW\n\n(remote) L2\n", again[0].RawStack)
+		require.Equal(t, got.Stack(), again.Err().(StackfulError).Stack())
+	})
+
+	t.Run("common-frames", func(t *testing.T) {
+		frame := func(name string) Frame {
+			return Frame{Function: name, File: "f.go", Line: 1}
+		}
+		chain := ErrorChain{
+			{Message: "a", Stack: []Frame{frame("a"), frame("main")}},
+			{Message: "b", Stack: []Frame{frame("b"), frame("a"), frame("main")}},
+		}
+		require.Equal(t, "a\n--- (remote) [f.go:1] a\n(remote) [f.go:1] main\n"+
+			"=== b\n--- (remote) [f.go:1] b\n... 2 frames in common with above\n",
+			Describe(chain.Err()))
+	})
+}
+
+func TestUnmarshalChain(t *testing.T) {
+	t.Run("invalid", func(t *testing.T) {
+		chain, err := UnmarshalChain([]byte(`{`))
+		require.Error(t, err)
+		require.Nil(t, chain)
+	})
+
+	t.Run("null", func(t *testing.T) {
+		chain, err := UnmarshalChain([]byte(`null`))
+		require.NoError(t, err)
+		require.Nil(t, chain.Err())
+	})
+
+	t.Run("biz", func(t *testing.T) {
+		chain, err := UnmarshalChain([]byte(`[{"message":"(0) ok","type":"*errx.bizErr","code":0,"bizMessage":"ok"}]`))
+		require.NoError(t, err)
+
+		biz, ok := chain.Err().(BizError)
+		require.True(t, ok)
+		require.Equal(t, 0, biz.Code())
+		require.Equal(t, "ok", biz.Message())
+		require.Nil(t, biz.Cause())
+		require.Equal(t, "", biz.Stack())
+	})
+}
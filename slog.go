@@ -0,0 +1,214 @@
+//go:build go1.21
+
+package errx
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strconv"
+)
+
+// 此文件提供 log/slog 的支持。 log/slog 自 Go 1.21 起可用，为保持对更低版本的兼容，此文件仅在 Go 1.21 及以上编译。
+
+var _ slog.LogValuer = (*ErrorWrapper)(nil)
+var _ slog.LogValuer = (*bizErr)(nil)
+var _ slog.LogValuer = (*PanicError)(nil)
+var _ slog.LogValuer = (*Multi)(nil)
+var _ slog.LogValuer = (*wrapErrors)(nil)
+var _ slog.LogValuer = (*RemoteError)(nil)
+var _ slog.LogValuer = (*RemoteBizError)(nil)
+var _ slog.LogValuer = (*RemoteMulti)(nil)
+
+// LogValue 实现 slog.LogValuer ，以一组结构化的属性输出整个错误链，格式见 ErrorLogValue() 。
+func (w *ErrorWrapper) LogValue() slog.Value {
+	return ErrorLogValue(w)
+}
+
+// LogValue 实现 slog.LogValuer ，以一组结构化的属性输出整个错误链，格式见 ErrorLogValue() 。
+func (e *bizErr) LogValue() slog.Value {
+	return ErrorLogValue(e)
+}
+
+// LogValue 实现 slog.LogValuer ，以一组结构化的属性输出整个错误链，格式见 ErrorLogValue() 。
+func (e *PanicError) LogValue() slog.Value {
+	return ErrorLogValue(e)
+}
+
+// LogValue 实现 slog.LogValuer ，以一组结构化的属性输出整个错误链，各个错误在 errors 中，格式见 ErrorLogValue() 。
+func (m *Multi) LogValue() slog.Value {
+	return ErrorLogValue(m)
+}
+
+// LogValue 实现 slog.LogValuer ，以一组结构化的属性输出整个错误链，各个错误在 errors 中，格式见 ErrorLogValue() 。
+func (e *wrapErrors) LogValue() slog.Value {
+	return ErrorLogValue(e)
+}
+
+// LogValue 实现 slog.LogValuer ，以一组结构化的属性输出整个错误链，格式见 ErrorLogValue() 。
+func (e *RemoteError) LogValue() slog.Value {
+	return ErrorLogValue(e)
+}
+
+// LogValue 实现 slog.LogValuer ，以一组结构化的属性输出整个错误链，格式见 ErrorLogValue() 。
+func (e *RemoteBizError) LogValue() slog.Value {
+	return ErrorLogValue(e)
+}
+
+// LogValue 实现 slog.LogValuer ，以一组结构化的属性输出整个错误链，各个内部错误在 errors 中，格式见 ErrorLogValue() 。
+func (e *RemoteMulti) LogValue() slog.Value {
+	return ErrorLogValue(e)
+}
+
+// ErrorLogValue 将给定的错误转换为 slog.Value 。若给定 nil ，返回空的 slog.Value 。
+//
+// 得到的是一个 group ，包含以下属性，无对应数据的属性被省略：
+//   - msg ：错误的描述。对于 StackfulError 是 ErrorWithoutStack() ，其他错误是 Error() ；
+//   - type ：错误的 Go 类型；
+//   - code ：BizError 的错误码；
+//   - fields ：当前这一层错误上的键值对，是一个 group ，仅在错误实现 FieldCarrier 时输出；
+//   - frames ：调用栈，是一个 []Frame ，仅在错误实现 StackTracer 时输出；
+//   - cause ：内部错误，即 errors.Unwrap() 的结果，是一个同样格式的 group ；
+//   - errors ：有多个内部错误（实现 Unwrap() []error ，如 Multi 和 errors.Join() 的结果）时，代替 cause 输出，
+//     是一个 group ，以序号“1”、“2”……为键，值是各个内部错误的同样格式的 group 。
+//
+// 与 Describe() 一样，错误链中的环和超出 DefaultMaxDepth 限制的部分，以字符串“<cycle detected>”和
+// “<truncated N more>”代替 cause 或 errors 中对应的 group 。
+func ErrorLogValue(err error) slog.Value {
+	if err == nil {
+		return slog.Value{}
+	}
+
+	visited := make(errorSet)
+	visited.add(err)
+	return errorLogValue(err, visited, 0)
+}
+
+// errorLogValue 实现 ErrorLogValue() 。 visited 是当前路径上的错误，包括 err 本身， depth 是 err 所在的层数。
+func errorLogValue(err error, visited errorSet, depth int) slog.Value {
+	attrs := make([]slog.Attr, 0, 6)
+
+	if se, ok := err.(StackfulError); ok {
+		attrs = append(attrs, slog.String("msg", se.ErrorWithoutStack()))
+	} else {
+		attrs = append(attrs, slog.String("msg", err.Error()))
+	}
+
+	attrs = append(attrs, slog.String("type", fmt.Sprintf("%T", err)))
+
+	if biz, ok := err.(BizError); ok {
+		attrs = append(attrs, slog.Int("code", biz.Code()))
+	}
+
+	if fc, ok := err.(FieldCarrier); ok {
+		if fields := fc.Fields(); len(fields) > 0 {
+			fieldAttrs := make([]slog.Attr, len(fields))
+			for i, f := range fields {
+				fieldAttrs[i] = slog.Any(f.Key, f.Value)
+			}
+			attrs = append(attrs, slog.Attr{Key: "fields", Value: slog.GroupValue(fieldAttrs...)})
+		}
+	}
+
+	if st, ok := err.(StackTracer); ok {
+		if frames := st.Frames(); len(frames) > 0 {
+			attrs = append(attrs, slog.Any("frames", frames))
+		}
+	}
+
+	if multi, ok := err.(interface{ Unwrap() []error }); ok {
+		var branches []slog.Attr
+		for _, e := range multi.Unwrap() {
+			if e != nil {
+				branches = append(branches, slog.Attr{Key: strconv.Itoa(len(branches) + 1), Value: innerLogValue(e, visited, depth+1)})
+			}
+		}
+		if len(branches) > 0 {
+			attrs = append(attrs, slog.Attr{Key: "errors", Value: slog.GroupValue(branches...)})
+		}
+	} else if cause := errors.Unwrap(err); cause != nil {
+		attrs = append(attrs, slog.Attr{Key: "cause", Value: innerLogValue(cause, visited, depth+1)})
+	}
+
+	return slog.GroupValue(attrs...)
+}
+
+// innerLogValue 返回内部错误的 slog.Value ，参数的含义同 errorLogValue() ，但 visited 尚不包括 err 。
+func innerLogValue(err error, visited errorSet, depth int) slog.Value {
+	if chainDepthExceeded(depth, DefaultMaxDepth) {
+		return slog.StringValue(truncatedMarker(err, visited))
+	}
+
+	if !visited.add(err) {
+		return slog.StringValue(cycleMarker)
+	}
+	defer visited.remove(err)
+
+	return errorLogValue(err, visited, depth)
+}
+
+// NewSlogHandler 包装给定的 slog.Handler ，将日志记录中的错误展开为结构化的属性，格式见 ErrorLogValue() 。
+//
+// Wrap() 、 NewBizError() 等创建的错误本身实现了 slog.LogValuer ，不需要此 Handler 也会被展开。
+// 此 Handler 还会展开其他错误链中（包括各个分支中）含有 StackfulError 的错误，
+// 如 fmt.Errorf("...: %w", errx.Wrap(...)) 和 errors.Join(errx.Wrap(...)) ，以及 group 中和通过 With() 添加的属性中的错误。
+func NewSlogHandler(h slog.Handler) slog.Handler {
+	return &slogHandler{h}
+}
+
+type slogHandler struct {
+	h slog.Handler
+}
+
+func (h *slogHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.h.Enabled(ctx, level)
+}
+
+func (h *slogHandler) Handle(ctx context.Context, r slog.Record) error {
+	nr := slog.NewRecord(r.Time, r.Level, r.Message, r.PC)
+	r.Attrs(func(a slog.Attr) bool {
+		nr.AddAttrs(expandErrorAttr(a))
+		return true
+	})
+	return h.h.Handle(ctx, nr)
+}
+
+func (h *slogHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	expanded := make([]slog.Attr, len(attrs))
+	for i, a := range attrs {
+		expanded[i] = expandErrorAttr(a)
+	}
+	return &slogHandler{h.h.WithAttrs(expanded)}
+}
+
+func (h *slogHandler) WithGroup(name string) slog.Handler {
+	return &slogHandler{h.h.WithGroup(name)}
+}
+
+// expandErrorAttr 若属性的值是错误链中含有 StackfulError 的错误，则将其展开；若是 group ，则递归处理其中的属性。
+func expandErrorAttr(a slog.Attr) slog.Attr {
+	v := a.Value.Resolve()
+
+	switch v.Kind() {
+	case slog.KindAny:
+		if err, ok := v.Any().(error); ok && chainHasStackful(err) {
+			return slog.Attr{Key: a.Key, Value: ErrorLogValue(err)}
+		}
+
+	case slog.KindGroup:
+		group := v.Group()
+		expanded := make([]slog.Attr, len(group))
+		for i, ga := range group {
+			expanded[i] = expandErrorAttr(ga)
+		}
+		return slog.Attr{Key: a.Key, Value: slog.GroupValue(expanded...)}
+	}
+
+	return slog.Attr{Key: a.Key, Value: v}
+}
+
+// chainHasStackful 判断给定的错误链中（包括 Unwrap() []error 的各个分支中）是否有 StackfulError 。
+func chainHasStackful(err error) bool {
+	return FindStackful(err) != nil
+}
@@ -0,0 +1,226 @@
+//go:build go1.21
+
+package errx
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestErrorLogValue(t *testing.T) {
+	t.Run("nil", func(t *testing.T) {
+		require.Equal(t, slog.Value{}, ErrorLogValue(nil))
+	})
+
+	t.Run("chain", func(t *testing.T) {
+		err := Wrap("p1", NewBizError(12, "biz", errors.New("gg")))
+		v := ErrorLogValue(err)
+		require.Equal(t, slog.KindGroup, v.Kind())
+
+		attrs := attrMap(v)
+		require.Equal(t, "p1: (12) biz", attrs["msg"].String())
+		require.Equal(t, "*errx.ErrorWrapper", attrs["type"].String())
+		require.NotContains(t, attrs, "code")
+		require.Equal(t, "github.com/cmstar/go-errx.TestErrorLogValue.func2", attrs["frames"].Any().([]Frame)[0].Function)
+
+		attrs = attrMap(attrs["cause"])
+		require.Equal(t, "(12) biz", attrs["msg"].String())
+		require.Equal(t, int64(12), attrs["code"].Int64())
+		require.Contains(t, attrs, "frames")
+
+		attrs = attrMap(attrs["cause"])
+		require.Equal(t, "gg", attrs["msg"].String())
+		require.Equal(t, "*errors.errorString", attrs["type"].String())
+		require.NotContains(t, attrs, "frames")
+		require.NotContains(t, attrs, "cause")
+	})
+
+	t.Run("fields", func(t *testing.T) {
+		err := With(With(WrapWithoutStack("p1", nil), "a", 1), "b", "x")
+		attrs := attrMap(ErrorLogValue(err))
+		fields := attrMap(attrs["fields"])
+		require.Equal(t, int64(1), fields["a"].Int64())
+		require.Equal(t, "x", fields["b"].String())
+		require.NotContains(t, attrs, "frames")
+	})
+
+	t.Run("branches", func(t *testing.T) {
+		m := NewMulti("m").Append(NewBizError(2, "b", nil), nil, errors.New("c"))
+		attrs := attrMap(ErrorLogValue(Wrap("p1", m)))
+
+		attrs = attrMap(attrs["cause"])
+		require.Equal(t, "*errx.Multi", attrs["type"].String())
+		require.Contains(t, attrs, "frames")
+		require.NotContains(t, attrs, "cause")
+
+		branches := attrMap(attrs["errors"])
+		require.Len(t, branches, 2)
+		require.Equal(t, int64(2), attrMap(branches["1"])["code"].Int64())
+		require.Equal(t, "c", attrMap(branches["2"])["msg"].String())
+
+		// 非本包的错误同样展开各个分支。
+		attrs = attrMap(ErrorLogValue(errors.Join(errors.New("a"), Wrap("b", nil))))
+		require.Equal(t, "a", attrMap(attrMap(attrs["errors"])["1"])["msg"].String())
+		require.Contains(t, attrMap(attrMap(attrs["errors"])["2"]), "frames")
+	})
+
+	t.Run("cycle", func(t *testing.T) {
+		w := Wrap("a", nil).(*ErrorWrapper)
+		w.Err = w
+		attrs := attrMap(w.LogValue())
+		require.Equal(t, "a: <cycle detected>", attrs["msg"].String())
+		require.Equal(t, "<cycle detected>", attrs["cause"].String())
+
+		// 同一个错误出现在多个分支中，不是环。
+		e := errors.New("e")
+		m := NewMulti("m").Append(e, e)
+		m.Append(m)
+		branches := attrMap(attrMap(m.LogValue())["errors"])
+		require.Equal(t, "e", attrMap(branches["1"])["msg"].String())
+		require.Equal(t, "e", attrMap(branches["2"])["msg"].String())
+		require.Equal(t, "<cycle detected>", branches["3"].String())
+	})
+
+	t.Run("depth", func(t *testing.T) {
+		var err error = errors.New("root")
+		for i := 0; i < DefaultMaxDepth+10; i++ {
+			err = WrapWithoutStack("w", err)
+		}
+
+		v := ErrorLogValue(err)
+		for i := 0; i < DefaultMaxDepth; i++ {
+			v = attrMap(v)["cause"]
+		}
+		require.Equal(t, "<truncated 11 more>", v.String())
+	})
+
+	t.Run("log-valuer", func(t *testing.T) {
+		require.Equal(t, ErrorLogValue(Wrap("p1", nil)).Kind(), Wrap("p1", nil).(slog.LogValuer).LogValue().Kind())
+		require.Equal(t, slog.KindGroup, NewBizError(1, "biz", nil).(slog.LogValuer).LogValue().Kind())
+
+		m := NewMulti("m").Append(errors.New("a"))
+		require.Equal(t, ErrorLogValue(m).String(), m.LogValue().String())
+
+		// RemoteBizError 和 RemoteMulti 不应退化为其内嵌的 RemoteError 。
+		got := NewErrorChain(NewBizError(3, "biz", nil)).Err()
+		require.Equal(t, int64(3), attrMap(got.(slog.LogValuer).LogValue())["code"].Int64())
+
+		got = NewErrorChain(m).Err()
+		require.Contains(t, attrMap(got.(slog.LogValuer).LogValue()), "errors")
+
+		got = ErrorChain{{Message: "r", Type: "x"}}.Err()
+		require.Equal(t, "r", attrMap(got.(slog.LogValuer).LogValue())["msg"].String())
+	})
+}
+
+func TestNewSlogHandler(t *testing.T) {
+	log := func(useHandler bool, f func(l *slog.Logger)) map[string]interface{} {
+		buf := new(bytes.Buffer)
+		var h slog.Handler = slog.NewJSONHandler(buf, nil)
+		if useHandler {
+			h = NewSlogHandler(h)
+		}
+		f(slog.New(h))
+
+		var res map[string]interface{}
+		require.NoError(t, json.Unmarshal(buf.Bytes(), &res))
+		return res
+	}
+
+	t.Run("log-valuer", func(t *testing.T) {
+		// 没有 Handler 也会被展开。
+		res := log(false, func(l *slog.Logger) {
+			l.Error("failed", "err", NewBizError(3, "biz", nil))
+		})
+		e := res["err"].(map[string]interface{})
+		require.Equal(t, "(3) biz", e["msg"])
+		require.Equal(t, float64(3), e["code"])
+		frames := e["frames"].([]interface{})
+		require.Equal(t, "github.com/cmstar/go-errx", frames[0].(map[string]interface{})["package"])
+	})
+
+	t.Run("foreign", func(t *testing.T) {
+		err := fmt.Errorf("outer: %w", NewBizErrorWithoutStack(1, "biz", nil))
+
+		res := log(false, func(l *slog.Logger) {
+			l.Error("failed", "err", err)
+		})
+		require.Equal(t, "outer: (1) biz", res["err"])
+
+		res = log(true, func(l *slog.Logger) {
+			l.Error("failed", "err", err, "plain", errors.New("plain"), "n", 1)
+		})
+		e := res["err"].(map[string]interface{})
+		require.Equal(t, "outer: (1) biz", e["msg"])
+		require.Equal(t, "*fmt.wrapError", e["type"])
+		require.Equal(t, "(1) biz", e["cause"].(map[string]interface{})["msg"])
+		require.Equal(t, "plain", res["plain"])
+		require.Equal(t, float64(1), res["n"])
+	})
+
+	t.Run("branches", func(t *testing.T) {
+		err := errors.Join(errors.New("a"), NewBizErrorWithoutStack(1, "biz", nil))
+		res := log(true, func(l *slog.Logger) {
+			l.Error("failed", "err", err)
+		})
+		e := res["err"].(map[string]interface{})
+		require.Equal(t, "a\n(1) biz", e["msg"])
+		branches := e["errors"].(map[string]interface{})
+		require.Equal(t, "a", branches["1"].(map[string]interface{})["msg"])
+		require.Equal(t, float64(1), branches["2"].(map[string]interface{})["code"])
+
+		// 没有 errx 的错误则不展开。
+		res = log(true, func(l *slog.Logger) {
+			l.Error("failed", "err", errors.Join(errors.New("a")))
+		})
+		require.Equal(t, "a", res["err"])
+	})
+
+	t.Run("cycle", func(t *testing.T) {
+		// 不含 StackfulError 的环也能正常结束。
+		c := &cycleError{name: "c"}
+		c.next = errors.Join(errors.New("x"), c)
+		require.False(t, chainHasStackful(c))
+
+		w := WrapWithoutStack("a", nil).(*ErrorWrapper)
+		w.Err = w
+		res := log(true, func(l *slog.Logger) {
+			l.Error("failed", "err", fmt.Errorf("outer: %w", w))
+		})
+		e := res["err"].(map[string]interface{})
+		require.Equal(t, "<cycle detected>", e["cause"].(map[string]interface{})["cause"])
+	})
+
+	t.Run("group-and-with", func(t *testing.T) {
+		err := fmt.Errorf("outer: %w", NewBizErrorWithoutStack(1, "biz", nil))
+		res := log(true, func(l *slog.Logger) {
+			l.With("werr", err).WithGroup("g").Error("failed", slog.Group("sub", "err", err))
+		})
+		require.Equal(t, "outer: (1) biz", res["werr"].(map[string]interface{})["msg"])
+
+		g := res["g"].(map[string]interface{})
+		sub := g["sub"].(map[string]interface{})
+		require.Equal(t, "outer: (1) biz", sub["err"].(map[string]interface{})["msg"])
+	})
+
+	t.Run("enabled", func(t *testing.T) {
+		h := NewSlogHandler(slog.NewJSONHandler(new(bytes.Buffer), &slog.HandlerOptions{Level: slog.LevelWarn}))
+		require.False(t, h.Enabled(context.Background(), slog.LevelInfo))
+		require.True(t, h.Enabled(context.Background(), slog.LevelError))
+	})
+}
+
+func attrMap(v slog.Value) map[string]slog.Value {
+	res := make(map[string]slog.Value)
+	for _, a := range v.Group() {
+		res[a.Key] = a.Value
+	}
+	return res
+}
@@ -4,6 +4,7 @@ import (
 	"runtime"
 	"strconv"
 	"strings"
+	"sync"
 )
 
 // StackfulError 是一个包含调用栈信息的 error 。
@@ -38,20 +39,45 @@ func (e ErrorCause) Cause() error {
 	return e.Err
 }
 
-// frame 存放了 runtime.Frame 的部分字段，用于自定义输出格式。
-type frame struct {
-	name string // 方法名称。
-	line string // 行号。
-	file string // 文件名。
+// StackTracer 是可以提供结构化调用栈信息的类型。 ErrorStack 实现此接口，
+// 因此 Wrap() 、 NewBizError() 等创建的错误也都实现此接口。
+//
+// 日志等工具可通过此接口获取调用栈的各个字段，而不必解析 StackfulError.Stack() 输出的文本。
+type StackTracer interface {
+	// Frames 返回调用栈的各层信息，第一个元素是最内层（即创建错误处）的调用。若未记录调用栈，返回空值。
+	Frames() []Frame
+}
+
+// Frame 表示调用栈中的一层调用，存放了 runtime.Frame 的部分字段。
+type Frame struct {
+	Function string  `json:"function"` // 函数的完整名称，如 github.com/user/pkg.(*T).Method 。
+	Package  string  `json:"package"`  // 函数所在包的路径，如 github.com/user/pkg 。
+	File     string  `json:"file"`     // 文件的完整路径。
+	Line     int     `json:"line"`     // 行号。
+	PC       uintptr `json:"-"`        // 程序计数器，仅在当前进程内有意义，不参与序列化。
+
+	// Remote 表示此 Frame 来自另一个进程，见 RemoteError 。
+	Remote bool `json:"remote,omitempty"`
+}
+
+// ShortName 从完整的函数名称中获取短名称，去掉路径部分： github.com/user/pkg.Name -> pkg.Name 。
+func (f Frame) ShortName() string {
+	idx := strings.LastIndex(f.Function, "/")
+	if idx < 0 {
+		return f.Function
+	}
+	return f.Function[idx+1:]
 }
 
-// shortFuncName 从一个完整的函数描述中获取短名称，去掉路径部分： github.com/user/pkg.Name -> pkg.Name 。
-func (f frame) shortName() string {
-	idx := strings.LastIndex(f.name, "/")
+// packageName 从完整的函数名称中获取包路径： github.com/user/pkg.(*T).Method -> github.com/user/pkg 。
+func packageName(funcName string) string {
+	// 函数名称中，包路径最后一段的“.”会被转义为“%2e”，其后的第一个“.”即是包名与函数名的分界。
+	start := strings.LastIndex(funcName, "/") + 1
+	idx := strings.Index(funcName[start:], ".")
 	if idx < 0 {
-		return f.name
+		return ""
 	}
-	return f.name[idx+1:]
+	return strings.ReplaceAll(funcName[:start+idx], "%2e", ".")
 }
 
 // ErrorStack 用于存放调用栈信息，以便实现 StackfulError 。
@@ -60,81 +86,168 @@ func (f frame) shortName() string {
 //	[file0:line] func0
 //	[file1:line] func1
 //	[file2:line] func2
+//
+// 来自其他进程的 Frame （见 RemoteError ）带有前缀“(remote) ”。
+//
+// 创建时仅记录调用栈的原始 PC ，在首次需要输出时才解析为具体的函数、文件和行号，解析结果会被缓存。
+// 多数错误并不会被输出，这样可以省去大部分解析调用栈的开销。
 type ErrorStack struct {
-	frames []frame
+	st *stack
 }
 
+// stack 存放 ErrorStack 的数据。 ErrorStack 通常以值的形式被嵌入，通过指针共享解析结果。
+type stack struct {
+	pcs    []uintptr // runtime.Callers() 得到的原始数据。
+	once   sync.Once
+	frames []Frame // 由 pcs 解析得到，在首次使用时才初始化。
+}
+
+var _ StackTracer = ErrorStack{}
+
 // Stack 实现 StackfulError.Stack() 。
 func (e ErrorStack) Stack() string {
 	b := new(strings.Builder)
-	for i := 0; i < len(e.frames); i++ {
-		f := e.frames[i]
-		b.WriteRune('[')
-		b.WriteString(f.file)
-		b.WriteRune(':')
-		b.WriteString(f.line)
-		b.WriteString("] ")
-		b.WriteString(f.shortName())
-		b.WriteRune('\n')
-	}
+	writeFrames(b, e.resolve())
 	return b.String()
 }
 
+// Frames 实现 StackTracer.Frames() 。返回的是一个副本，对其修改不影响 ErrorStack 。
+func (e ErrorStack) Frames() []Frame {
+	frames := e.resolve()
+	if len(frames) == 0 {
+		return nil
+	}
+
+	res := make([]Frame, len(frames))
+	copy(res, frames)
+	return res
+}
+
+// newResolvedStack 使用已解析的调用栈创建 ErrorStack ，用于调用栈并非来自当前进程的情形。
+func newResolvedStack(frames []Frame) ErrorStack {
+	if len(frames) == 0 {
+		return ErrorStack{}
+	}
+
+	st := &stack{frames: frames}
+	st.once.Do(func() {}) // 已解析，不再需要通过 pcs 解析。
+	return ErrorStack{st}
+}
+
+// hasStack 返回是否记录了调用栈。与 Stack() 不同，它不需要解析调用栈。
+func (e ErrorStack) hasStack() bool {
+	return e.st != nil && (len(e.st.pcs) > 0 || len(e.st.frames) > 0)
+}
+
+// resolve 返回解析后的调用栈。解析仅在首次调用时进行，之后直接返回缓存的结果。
+func (e ErrorStack) resolve() []Frame {
+	if e.st == nil {
+		return nil
+	}
+
+	e.st.once.Do(func() {
+		e.st.frames = symbolize(e.st.pcs)
+	})
+	return e.st.frames
+}
+
 // GetErrorStack 创建一个带有调用栈信息的 ErrorStack 。
-// 调用栈信息使用 runtime.CallersFrames() 获取，skip 参数传递给 runtime.Callers() 。
+// 调用栈信息使用 runtime.Callers() 获取，skip 参数传递给 runtime.Callers() 。
 // 要跳过当前函数，至少为 2 ：分别跳过 runtime.Callers() 和当前函数。
+//
+// 此方法仅记录原始的 PC ，其对应的函数、文件和行号在首次输出时通过 runtime.CallersFrames() 解析。
 func GetErrorStack(skip int) ErrorStack {
 	const batchSize = 24 // Go 的调用层级通常不会很多，此大小足够应付多数场景。
 
-	var localFrames []frame
-	ps := make([]uintptr, batchSize)
+	pcs := make([]uintptr, batchSize)
 	for {
-		num := runtime.Callers(skip, ps)
-		runtimeFrames := runtime.CallersFrames(ps[:num])
-
-		if localFrames == nil {
-			localFrames = make([]frame, 0, num)
+		num := runtime.Callers(skip, pcs)
+		if num < len(pcs) {
+			pcs = pcs[:num]
+			break
 		}
 
-		for {
-			f, more := runtimeFrames.Next()
+		// 当 num == len(pcs) ，说明可能还没获取完整，扩大缓冲区重新获取。
+		pcs = make([]uintptr, len(pcs)*2)
+	}
 
-			name := f.Func.Name()
-			localFrames = append(localFrames, frame{
-				name: name,
-				file: f.File,
-				line: strconv.Itoa(f.Line),
-			})
+	return ErrorStack{&stack{pcs: pcs}}
+}
 
-			if !more {
-				break
-			}
-		}
+// symbolize 将 runtime.Callers() 得到的 PC 解析为 Frame 。
+func symbolize(pcs []uintptr) []Frame {
+	if len(pcs) == 0 {
+		return nil
+	}
+
+	localFrames := make([]Frame, 0, len(pcs))
+	runtimeFrames := runtime.CallersFrames(pcs)
+	for {
+		f, more := runtimeFrames.Next()
+
+		localFrames = append(localFrames, Frame{
+			Function: f.Function,
+			Package:  packageName(f.Function),
+			File:     f.File,
+			Line:     f.Line,
+			PC:       f.PC,
+		})
 
-		if num < batchSize {
+		if !more {
 			break
 		}
-
-		// 当 num == batchSize ，说明可能还没获取完整，推进到下一批次。
-		skip += batchSize
 	}
 
 	// 将末尾的系统调用去掉，让信息“干净”点。
-	localFrames = excludeRuntimeFrame(localFrames)
-	return ErrorStack{localFrames}
+	return excludeRuntimeFrame(localFrames)
+}
+
+// writeFrames 以 ErrorStack.Stack() 的格式输出给定的调用栈，每行一个 Frame 。
+// 来自其他进程的 Frame 带有前缀“(remote) ”。
+func writeFrames(b *strings.Builder, frames []Frame) {
+	for i := 0; i < len(frames); i++ {
+		f := frames[i]
+		if f.Remote {
+			b.WriteString("(remote) ")
+		}
+		b.WriteRune('[')
+		b.WriteString(f.File)
+		b.WriteRune(':')
+		b.WriteString(strconv.Itoa(f.Line))
+		b.WriteString("] ")
+		b.WriteString(f.ShortName())
+		b.WriteRune('\n')
+	}
+}
+
+// commonFrames 返回 frames 与 above 末尾相同的 Frame 的数量，即两个调用栈共同的外层调用的数量。
+// 至少保留 frames 的第一个元素（即错误的创建处）不计入，以便总能看到错误是在哪创建的。
+func commonFrames(frames, above []Frame) int {
+	n := 0
+	i, j := len(frames)-1, len(above)-1
+	for i > 0 && j >= 0 {
+		a, b := frames[i], above[j]
+		if a.Function != b.Function || a.File != b.File || a.Line != b.Line || a.Remote != b.Remote {
+			break
+		}
+		n++
+		i--
+		j--
+	}
+	return n
 }
 
 // excludeRuntimeFrame 将 fs 末尾的标准库 runtime 包的调用去掉。
-func excludeRuntimeFrame(fs []frame) []frame {
+func excludeRuntimeFrame(fs []Frame) []Frame {
 	var i int
-	var f frame
+	var f Frame
 	for i = len(fs) - 1; i >= 0; i-- {
 		f = fs[i]
-		if f.file == "" || f.line == "0" {
+		if f.File == "" || f.Line == 0 {
 			// 最底下可能有个什么信息都没有的调用，应该来自非 GO 代码。
 			continue
 		}
-		if !strings.HasPrefix(f.name, "runtime.") {
+		if !strings.HasPrefix(f.Function, "runtime.") {
 			break
 		}
 	}
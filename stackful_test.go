@@ -2,7 +2,10 @@ package errx
 
 import (
 	"bufio"
+	"errors"
+	"fmt"
 	"regexp"
+	"runtime"
 	"strconv"
 	"strings"
 	"testing"
@@ -70,3 +73,268 @@ func (c *caller) call(recursiveLevel int) ErrorStack {
 	c.count++
 	return c.call(recursiveLevel)
 }
+
+func TestErrorStack_lazy(t *testing.T) {
+	t.Run("zero", func(t *testing.T) {
+		var e ErrorStack
+		require.Nil(t, e.resolve())
+		require.Equal(t, "", e.Stack())
+	})
+
+	t.Run("cached", func(t *testing.T) {
+		e := GetErrorStack(2)
+		require.NotEmpty(t, e.st.pcs)
+		require.Nil(t, e.st.frames, "frames should not be resolved before use")
+
+		s := e.Stack()
+		require.Regexp(t, `^\[.+stackful_test\.go:\d+\] go-errx\.TestErrorStack_lazy`, s)
+		require.NotNil(t, e.st.frames)
+
+		// 复制后的值共享解析结果。
+		copied := e
+		require.Equal(t, s, copied.Stack())
+		require.Equal(t, &e.st.frames[0], &copied.resolve()[0])
+	})
+}
+
+// 以下 Benchmark 对比延迟解析（lazy）与早期版本在创建时立即解析（eager）调用栈的开销。
+// eager 使用下面的 eagerStack ，它复制自早期版本的 GetErrorStack() 。
+
+// eagerFrame 复制自早期版本的 frame 。
+type eagerFrame struct {
+	name string // 方法名称。
+	line string // 行号。
+	file string // 文件名。
+}
+
+// eagerError 对应早期版本的 ErrorWrapper 和 bizErr ，调用栈在创建时即已解析。
+type eagerError struct {
+	ErrorCause
+	code   int
+	msg    string
+	frames []eagerFrame
+}
+
+func (e *eagerError) Error() string {
+	return e.msg
+}
+
+func newEagerError(code int, message string, cause error) *eagerError {
+	return &eagerError{
+		ErrorCause: ErrorCause{cause},
+		code:       code,
+		msg:        message,
+		frames:     eagerStack(3),
+	}
+}
+
+// eagerPreserveRecover 复制自早期版本的 PreserveRecover() 。
+func eagerPreserveRecover(message string, recovered interface{}) error {
+	if recovered == nil {
+		return nil
+	}
+
+	var cause error
+	switch e := recovered.(type) {
+	case error:
+		cause = e
+	case string:
+		cause = errors.New(e)
+	default:
+		cause = fmt.Errorf("%v", e)
+	}
+
+	return &eagerError{
+		ErrorCause: ErrorCause{cause},
+		msg:        message,
+		frames:     eagerStack(4),
+	}
+}
+
+// eagerStack 复制自早期版本的 GetErrorStack() ，在获取调用栈的同时通过 runtime.CallersFrames() 解析。
+func eagerStack(skip int) []eagerFrame {
+	const batchSize = 24
+
+	var localFrames []eagerFrame
+	ps := make([]uintptr, batchSize)
+	for {
+		num := runtime.Callers(skip, ps)
+		runtimeFrames := runtime.CallersFrames(ps[:num])
+
+		if localFrames == nil {
+			localFrames = make([]eagerFrame, 0, num)
+		}
+
+		for {
+			f, more := runtimeFrames.Next()
+
+			name := f.Func.Name()
+			localFrames = append(localFrames, eagerFrame{
+				name: name,
+				file: f.File,
+				line: strconv.Itoa(f.Line),
+			})
+
+			if !more {
+				break
+			}
+		}
+
+		if num < batchSize {
+			break
+		}
+		skip += batchSize
+	}
+
+	// 同早期版本的 excludeRuntimeFrame() 。
+	var i int
+	for i = len(localFrames) - 1; i >= 0; i-- {
+		f := localFrames[i]
+		if f.file == "" || f.line == "0" {
+			continue
+		}
+		if !strings.HasPrefix(f.name, "runtime.") {
+			break
+		}
+	}
+	return localFrames[:i+1]
+}
+
+func BenchmarkWrap(b *testing.B) {
+	cause := errors.New("cause")
+
+	b.Run("lazy", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			_ = Wrap("msg", cause)
+		}
+	})
+
+	b.Run("eager", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			_ = newEagerError(0, "msg", cause)
+		}
+	})
+}
+
+func BenchmarkNewBizError(b *testing.B) {
+	cause := errors.New("cause")
+
+	b.Run("lazy", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			_ = NewBizError(1, "msg", cause)
+		}
+	})
+
+	b.Run("eager", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			_ = newEagerError(1, "msg", cause)
+		}
+	})
+}
+
+func BenchmarkPreserveRecover(b *testing.B) {
+	run := func(preserve func(string, interface{}) error) error {
+		return func() (err error) {
+			defer func() {
+				err = preserve("msg", recover())
+			}()
+			panic("gg")
+		}()
+	}
+
+	lazy := func(message string, recovered interface{}) error {
+		return PreserveRecover(message, recovered)
+	}
+
+	b.Run("lazy", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			_ = run(lazy)
+		}
+	})
+
+	b.Run("eager", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			_ = run(eagerPreserveRecover)
+		}
+	})
+}
+
+func TestErrorStack_Frames(t *testing.T) {
+	t.Run("zero", func(t *testing.T) {
+		var e ErrorStack
+		require.Nil(t, e.Frames())
+	})
+
+	t.Run("frames", func(t *testing.T) {
+		frames := new(caller).call(2).Frames()
+		require.True(t, len(frames) > 4)
+
+		for i := 0; i < 3; i++ {
+			f := frames[i]
+			require.Equal(t, "github.com/cmstar/go-errx.(*caller).call", f.Function)
+			require.Equal(t, "github.com/cmstar/go-errx", f.Package)
+			require.Equal(t, "go-errx.(*caller).call", f.ShortName())
+			require.Regexp(t, `stackful_test\.go$`, f.File)
+			require.NotZero(t, f.Line)
+			require.NotZero(t, f.PC)
+		}
+		require.Regexp(t, `^github\.com/cmstar/go-errx\.TestErrorStack_Frames`, frames[3].Function)
+	})
+
+	t.Run("copy", func(t *testing.T) {
+		e := GetErrorStack(2)
+		frames := e.Frames()
+		frames[0].Function = "changed"
+		require.NotEqual(t, "changed", e.Frames()[0].Function)
+	})
+}
+
+func TestPackageName(t *testing.T) {
+	cases := map[string]string{
+		"":                                    "",
+		"main.main":                           "main",
+		"runtime.goexit":                      "runtime",
+		"github.com/user/pkg.Name":            "github.com/user/pkg",
+		"github.com/user/pkg.(*T).Method":     "github.com/user/pkg",
+		"github.com/user/pkg.Name.func1":      "github.com/user/pkg",
+		"github.com/user/go-errx.Wrap":        "github.com/user/go-errx",
+		"gopkg.in/yaml%2ev3.Unmarshal":        "gopkg.in/yaml.v3",
+		"github.com/user/pkg.F[...]":          "github.com/user/pkg",
+		"github.com/user/pkg/sub.(*T[...]).M": "github.com/user/pkg/sub",
+	}
+	for name, want := range cases {
+		require.Equal(t, want, packageName(name), name)
+	}
+}
+
+func TestCommonFrames(t *testing.T) {
+	fs := func(names ...string) []Frame {
+		res := make([]Frame, len(names))
+		for i, v := range names {
+			res[i] = Frame{Function: v, File: "f.go", Line: 1}
+		}
+		return res
+	}
+
+	require.Equal(t, 0, commonFrames(nil, nil))
+	require.Equal(t, 0, commonFrames(fs("a"), nil))
+	require.Equal(t, 0, commonFrames(fs("a", "b"), fs("c", "d")))
+	require.Equal(t, 2, commonFrames(fs("a", "b", "c"), fs("x", "b", "c")))
+	require.Equal(t, 2, commonFrames(fs("a", "b", "c"), fs("b", "c")))
+	require.Equal(t, 1, commonFrames(fs("a", "b", "c"), fs("c")))
+
+	// 至少保留第一个。
+	require.Equal(t, 2, commonFrames(fs("a", "b", "c"), fs("a", "b", "c")))
+	require.Equal(t, 0, commonFrames(fs("a"), fs("a")))
+
+	// 行号不同也视为不同。
+	other := fs("a", "b", "c")
+	other[1].Line = 2
+	require.Equal(t, 1, commonFrames(fs("a", "b", "c"), other))
+}
@@ -0,0 +1,167 @@
+package errx
+
+import (
+	"fmt"
+	"io"
+	"path"
+	"reflect"
+	"runtime"
+	"strconv"
+	"strings"
+)
+
+// 此文件提供与 github.com/pkg/errors 兼容的调用栈表示，见 StackTrace 。
+
+// StackFrame 是调用栈中的一层调用，与 github.com/pkg/errors 的 Frame 相同，其值是 runtime.Callers() 得到的 PC 。
+//
+// 它实现 fmt.Formatter ，支持的格式与 pkg/errors 相同：
+//   - %s ：文件名；
+//   - %d ：行号；
+//   - %n ：函数名；
+//   - %v ：相当于 %s:%d ；
+//   - %+s ：函数的完整名称和文件的完整路径，以“\n\t”分隔；
+//   - %+v ：相当于 %+s:%d 。
+type StackFrame uintptr
+
+// StackTrace 是调用栈，与 github.com/pkg/errors 的 StackTrace 相同，第一个元素是最内层的调用。
+// 可通过 ErrorStack.StackTrace() 获得。
+//
+// 它实现 fmt.Formatter ： %+v 每行输出一个 StackFrame 的 %+v ， %v 和 %s 以“[a b c]”的形式输出各个 StackFrame 。
+type StackTrace []StackFrame
+
+// StackTrace 返回 pkg/errors 风格的调用栈，以便兼容使用 pkg/errors 约定的工具，如
+// interface{ StackTrace() errors.StackTrace } 。若未记录调用栈，或调用栈并非来自当前进程（见 RemoteError ），返回 nil 。
+func (e ErrorStack) StackTrace() StackTrace {
+	if e.st == nil || len(e.st.pcs) == 0 {
+		return nil
+	}
+
+	res := make(StackTrace, len(e.st.pcs))
+	for i, pc := range e.st.pcs {
+		res[i] = StackFrame(pc)
+	}
+	return res
+}
+
+// pc 返回调用指令所在的 PC 。 runtime.Callers() 得到的是调用的下一条指令。
+func (f StackFrame) pc() uintptr {
+	return uintptr(f) - 1
+}
+
+// fileLine 返回文件的完整路径和行号，无法解析时返回“unknown”和 0 。
+func (f StackFrame) fileLine() (string, int) {
+	fn := runtime.FuncForPC(f.pc())
+	if fn == nil {
+		return "unknown", 0
+	}
+	return fn.FileLine(f.pc())
+}
+
+// name 返回函数的完整名称，无法解析时返回“unknown”。
+func (f StackFrame) name() string {
+	fn := runtime.FuncForPC(f.pc())
+	if fn == nil {
+		return "unknown"
+	}
+	return fn.Name()
+}
+
+// Format 实现 fmt.Formatter ，格式见 StackFrame 。
+func (f StackFrame) Format(s fmt.State, verb rune) {
+	switch verb {
+	case 's':
+		file, _ := f.fileLine()
+		if s.Flag('+') {
+			io.WriteString(s, f.name())
+			io.WriteString(s, "\n\t")
+			io.WriteString(s, file)
+		} else {
+			io.WriteString(s, path.Base(file))
+		}
+
+	case 'd':
+		_, line := f.fileLine()
+		io.WriteString(s, strconv.Itoa(line))
+
+	case 'n':
+		// 去掉包路径和包名： github.com/user/pkg.(*T).Method -> (*T).Method 。
+		name := f.name()
+		name = name[strings.LastIndex(name, "/")+1:]
+		io.WriteString(s, name[strings.Index(name, ".")+1:])
+
+	case 'v':
+		f.Format(s, 's')
+		io.WriteString(s, ":")
+		f.Format(s, 'd')
+	}
+}
+
+// MarshalText 实现 encoding.TextMarshaler ，格式为： 函数名 文件:行号 。无法解析时输出“unknown”。
+func (f StackFrame) MarshalText() ([]byte, error) {
+	name := f.name()
+	if name == "unknown" {
+		return []byte(name), nil
+	}
+
+	file, line := f.fileLine()
+	return []byte(name + " " + file + ":" + strconv.Itoa(line)), nil
+}
+
+// Format 实现 fmt.Formatter ，格式见 StackTrace 。
+func (st StackTrace) Format(s fmt.State, verb rune) {
+	switch verb {
+	case 'v':
+		switch {
+		case s.Flag('+'):
+			for _, f := range st {
+				io.WriteString(s, "\n")
+				f.Format(s, verb)
+			}
+		case s.Flag('#'):
+			fmt.Fprintf(s, "%#v", []StackFrame(st))
+		default:
+			st.formatSlice(s, verb)
+		}
+
+	case 's':
+		st.formatSlice(s, verb)
+	}
+}
+
+func (st StackTrace) formatSlice(s fmt.State, verb rune) {
+	io.WriteString(s, "[")
+	for i, f := range st {
+		if i > 0 {
+			io.WriteString(s, " ")
+		}
+		f.Format(s, verb)
+	}
+	io.WriteString(s, "]")
+}
+
+// foreignFrames 获取 StackfulError 以外的错误的调用栈：若错误实现 StackTracer ，使用其 Frames() ；
+// 否则若错误有 pkg/errors 风格的 StackTrace() 方法，即返回值是元素为 uintptr 的 slice ，将其解析为 Frame 。
+// 若都没有，返回 nil 。
+func foreignFrames(err error) []Frame {
+	if st, ok := err.(StackTracer); ok {
+		return st.Frames()
+	}
+
+	// pkg/errors 的 StackTrace 类型定义在其自身的包里，无法通过接口断言，只能通过反射调用。
+	m := reflect.ValueOf(err).MethodByName("StackTrace")
+	if !m.IsValid() {
+		return nil
+	}
+
+	t := m.Type()
+	if t.NumIn() != 0 || t.NumOut() != 1 || t.Out(0).Kind() != reflect.Slice || t.Out(0).Elem().Kind() != reflect.Uintptr {
+		return nil
+	}
+
+	v := m.Call(nil)[0]
+	pcs := make([]uintptr, v.Len())
+	for i := range pcs {
+		pcs[i] = uintptr(v.Index(i).Uint())
+	}
+	return symbolize(pcs)
+}
@@ -0,0 +1,96 @@
+package errx
+
+import (
+	"errors"
+	"fmt"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// pkgError 模拟 github.com/pkg/errors 创建的错误，其 StackTrace() 返回的类型定义在其自身的包里。
+type pkgError struct {
+	msg   string
+	stack pkgStackTrace
+}
+
+type pkgFrame uintptr
+type pkgStackTrace []pkgFrame
+
+func newPkgError(msg string) *pkgError {
+	var pcs [32]uintptr
+	n := runtime.Callers(2, pcs[:])
+
+	st := make(pkgStackTrace, n)
+	for i := 0; i < n; i++ {
+		st[i] = pkgFrame(pcs[i])
+	}
+	return &pkgError{msg, st}
+}
+
+func (e *pkgError) Error() string             { return e.msg }
+func (e *pkgError) StackTrace() pkgStackTrace { return e.stack }
+
+// stackTracerError 是实现 StackTracer 但不是 StackfulError 的错误。
+type stackTracerError struct {
+	ErrorStack
+}
+
+func (stackTracerError) Error() string { return "tracer" }
+
+func TestErrorStack_StackTrace(t *testing.T) {
+	require.Nil(t, ErrorStack{}.StackTrace())
+	require.Nil(t, newResolvedStack([]Frame{{Function: "f"}}).StackTrace())
+
+	err := Wrap("msg", nil)
+	st := err.(interface{ StackTrace() StackTrace }).StackTrace()
+	require.NotEmpty(t, st)
+
+	f := st[0]
+	require.Equal(t, "stacktrace_test.go", fmt.Sprintf("%s", f))
+	require.Regexp(t, `^\d+$`, fmt.Sprintf("%d", f))
+	require.Equal(t, "TestErrorStack_StackTrace", fmt.Sprintf("%n", f))
+	require.Regexp(t, `^stacktrace_test\.go:\d+$`, fmt.Sprintf("%v", f))
+	require.Regexp(t, `^github\.com/cmstar/go-errx\.TestErrorStack_StackTrace\n\t.+/stacktrace_test\.go:\d+$`, fmt.Sprintf("%+v", f))
+
+	text, e := f.MarshalText()
+	require.NoError(t, e)
+	require.Regexp(t, `^github\.com/cmstar/go-errx\.TestErrorStack_StackTrace .+/stacktrace_test\.go:\d+$`, string(text))
+
+	require.Regexp(t, `^\[stacktrace_test\.go:\d+ `, fmt.Sprintf("%v", st))
+	require.Regexp(t, `^\[stacktrace_test\.go `, fmt.Sprintf("%s", st))
+	require.Regexp(t, `^\ngithub\.com/cmstar/go-errx\.TestErrorStack_StackTrace\n\t`, fmt.Sprintf("%+v", st))
+	require.Regexp(t, `^\[\]errx\.StackFrame\{stacktrace_test\.go:\d+, `, fmt.Sprintf("%#v", st))
+
+	unknown := StackFrame(0)
+	require.Equal(t, "unknown", fmt.Sprintf("%s", unknown))
+	text, _ = unknown.MarshalText()
+	require.Equal(t, "unknown", string(text))
+}
+
+func TestForeignFrames(t *testing.T) {
+	require.Nil(t, foreignFrames(errors.New("gg")))
+
+	frames := foreignFrames(newPkgError("gg"))
+	require.NotEmpty(t, frames)
+	require.Equal(t, "github.com/cmstar/go-errx.TestForeignFrames", frames[0].Function)
+
+	frames = foreignFrames(stackTracerError{GetErrorStack(2)})
+	require.Equal(t, "github.com/cmstar/go-errx.TestForeignFrames", frames[0].Function)
+}
+
+func TestDescribe_ForeignStack(t *testing.T) {
+	err := Wrap("outer", fmt.Errorf("mid: %w", newPkgError("inner")))
+	require.Regexp(t, `^outer: mid: inner
+--- \[.+/stacktrace_test\.go:\d+\] go-errx\.TestDescribe_ForeignStack
+.+
+=== mid: inner
+=== inner
+--- \[.+/stacktrace_test\.go:\d+\] go-errx\.TestDescribe_ForeignStack
+\.\.\. \d+ frames in common with above
+$`, Describe(err))
+
+	chain := NewErrorChain(err)
+	require.Equal(t, "github.com/cmstar/go-errx.TestDescribe_ForeignStack", chain[2].Stack[0].Function)
+}
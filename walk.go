@@ -0,0 +1,111 @@
+package errx
+
+import (
+	"errors"
+)
+
+// Chain 通过 errors.Unwrap() 逐层获取内部错误，返回整个错误链，第一个元素是 err 本身，最后一个是最内层的错误。
+// 若 err 为 nil ，返回 nil 。
+//
+// 它不进入 Unwrap() []error 的分支，遇到这样的错误即停止，要遍历分支，使用 Walk() 。
+// 若错误链中有环（某一层的内部错误是其外层的错误），到重复的错误之前停止。
+func Chain(err error) []error {
+	var res []error
+	visited := make(errorSet)
+	for ; err != nil; err = errors.Unwrap(err) {
+		if !visited.add(err) {
+			break
+		}
+		res = append(res, err)
+	}
+	return res
+}
+
+// RootCause 返回错误链中最内层的错误，即 Chain() 的最后一个元素。若 err 为 nil ，返回 nil 。
+func RootCause(err error) error {
+	chain := Chain(err)
+	if len(chain) == 0 {
+		return nil
+	}
+	return chain[len(chain)-1]
+}
+
+// Walk 以深度优先的顺序遍历错误链，对每个错误调用 fn ， depth 是错误所在的层数， err 本身为 0 。
+// 若 fn 返回 false ，遍历停止。
+//
+// 与 Chain() 不同，它会进入 Unwrap() []error 的各个分支（如 errors.Join() 和 Multi ），分支中的错误的层数为其外层加一。
+// 同一个错误只访问一次，因此错误链中有环时也能正常结束。为 nil 的错误被忽略。
+func Walk(err error, fn func(depth int, e error) bool) {
+	walk(err, 0, make(errorSet), fn)
+}
+
+// walk 遍历 err 及其内部错误，若需要停止遍历，返回 false 。
+func walk(err error, depth int, visited errorSet, fn func(depth int, e error) bool) bool {
+	for ; err != nil; depth++ {
+		if !visited.add(err) {
+			return true
+		}
+
+		if !fn(depth, err) {
+			return false
+		}
+
+		if multi, ok := err.(interface{ Unwrap() []error }); ok {
+			for _, e := range multi.Unwrap() {
+				if !walk(e, depth+1, visited, fn) {
+					return false
+				}
+			}
+			return true
+		}
+
+		err = errors.Unwrap(err)
+	}
+	return true
+}
+
+// FindBizError 按 Walk() 的顺序查找第一个 BizError ，即最外层的 BizError 。若没有，返回 nil 。
+func FindBizError(err error) BizError {
+	var res BizError
+	Walk(err, func(_ int, e error) bool {
+		res, _ = e.(BizError)
+		return res == nil
+	})
+	return res
+}
+
+// FindInnermostBizError 按 Walk() 的顺序查找最后一个 BizError ，对于没有分支的错误链即是最内层的 BizError 。
+// 若没有，返回 nil 。
+func FindInnermostBizError(err error) BizError {
+	var res BizError
+	Walk(err, func(_ int, e error) bool {
+		if biz, ok := e.(BizError); ok {
+			res = biz
+		}
+		return true
+	})
+	return res
+}
+
+// FindStackful 按 Walk() 的顺序查找第一个 StackfulError ，即最外层的 StackfulError 。若没有，返回 nil 。
+func FindStackful(err error) StackfulError {
+	var res StackfulError
+	Walk(err, func(_ int, e error) bool {
+		res, _ = e.(StackfulError)
+		return res == nil
+	})
+	return res
+}
+
+// FindInnermostStackful 按 Walk() 的顺序查找最后一个 StackfulError ，对于没有分支的错误链即是最内层的 StackfulError ，
+// 其调用栈通常最接近错误发生的位置。若没有，返回 nil 。
+func FindInnermostStackful(err error) StackfulError {
+	var res StackfulError
+	Walk(err, func(_ int, e error) bool {
+		if se, ok := e.(StackfulError); ok {
+			res = se
+		}
+		return true
+	})
+	return res
+}
@@ -0,0 +1,150 @@
+package errx
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// cycleError 的内部错误可以被设置为其外层的错误，用于构造有环的错误链。
+type cycleError struct {
+	name string
+	next error
+}
+
+func (e *cycleError) Error() string { return e.name }
+func (e *cycleError) Unwrap() error { return e.next }
+
+// valueError 是值类型的错误，其 Unwrap 总是返回一个新的副本。
+type valueError struct {
+	data []int
+	next error
+}
+
+func (e valueError) Error() string { return "value" }
+func (e valueError) Unwrap() error { return e.next }
+
+func newCycle() (*cycleError, *cycleError) {
+	a := &cycleError{name: "a"}
+	b := &cycleError{name: "b", next: a}
+	a.next = b
+	return a, b
+}
+
+func TestChain(t *testing.T) {
+	require.Nil(t, Chain(nil))
+
+	e3 := errors.New("e3")
+	e2 := NewBizError(1, "e2", e3)
+	e1 := Wrap("e1", e2)
+	require.Equal(t, []error{e1, e2, e3}, Chain(e1))
+
+	a, b := newCycle()
+	require.Equal(t, []error{a, b}, Chain(a))
+
+	v := valueError{[]int{1}, e3}
+	require.Equal(t, []error{v, e3}, Chain(v))
+}
+
+func TestRootCause(t *testing.T) {
+	require.Nil(t, RootCause(nil))
+
+	e3 := errors.New("e3")
+	require.Equal(t, e3, RootCause(Wrap("e1", fmt.Errorf("e2: %w", e3))))
+	require.Equal(t, e3, RootCause(e3))
+
+	a, b := newCycle()
+	require.Equal(t, b, RootCause(a))
+}
+
+func TestWalk(t *testing.T) {
+	type visit struct {
+		depth int
+		msg   string
+	}
+
+	collect := func(err error, limit int) []visit {
+		var res []visit
+		Walk(err, func(depth int, e error) bool {
+			msg := e.Error()
+			if se, ok := e.(StackfulError); ok {
+				msg = se.ErrorWithoutStack()
+			}
+			res = append(res, visit{depth, msg})
+			return limit <= 0 || len(res) < limit
+		})
+		return res
+	}
+
+	t.Run("nil", func(t *testing.T) {
+		require.Nil(t, collect(nil, 0))
+	})
+
+	t.Run("branches", func(t *testing.T) {
+		m := (&Multi{msg: "m"}).Append(
+			WrapWithoutStack("b1", errors.New("b1-inner")),
+			errors.New("b2"),
+		)
+		err := WrapWithoutStack("top", m)
+
+		require.Equal(t, []visit{
+			{0, "top: m: b1: b1-inner; b2"},
+			{1, "m: b1: b1-inner; b2"},
+			{2, "b1: b1-inner"},
+			{3, "b1-inner"},
+			{2, "b2"},
+		}, collect(err, 0))
+
+		require.Equal(t, []visit{
+			{0, "top: m: b1: b1-inner; b2"},
+			{1, "m: b1: b1-inner; b2"},
+			{2, "b1: b1-inner"},
+		}, collect(err, 3))
+	})
+
+	t.Run("cycle", func(t *testing.T) {
+		a, _ := newCycle()
+		require.Equal(t, []visit{{0, "a"}, {1, "b"}}, collect(a, 0))
+
+		// 分支中引用了外层的错误。其 Error() 本身会无限递归，这里只比较错误本身。
+		m := &Multi{msg: "m"}
+		x := errors.New("x")
+		m.Append(m, x)
+
+		var got []error
+		var depths []int
+		Walk(m, func(depth int, e error) bool {
+			got = append(got, e)
+			depths = append(depths, depth)
+			return true
+		})
+		require.True(t, got[0] == error(m))
+		require.True(t, got[1] == x)
+		require.Len(t, got, 2)
+		require.Equal(t, []int{0, 1}, depths)
+	})
+}
+
+func TestFind(t *testing.T) {
+	inner := NewBizError(2, "inner", errors.New("gg"))
+	outer := NewBizError(1, "outer", Wrap("p", inner))
+	err := fmt.Errorf("top: %w", outer)
+
+	require.Equal(t, outer, FindBizError(err))
+	require.Equal(t, inner, FindInnermostBizError(err))
+	require.Equal(t, outer, FindStackful(err))
+	require.Equal(t, inner, FindInnermostStackful(err))
+
+	plain := errors.New("plain")
+	require.Nil(t, FindBizError(plain))
+	require.Nil(t, FindInnermostBizError(plain))
+	require.Nil(t, FindStackful(plain))
+	require.Nil(t, FindInnermostStackful(nil))
+
+	a, b := newCycle()
+	b.next = Wrap("w", a)
+	require.NotNil(t, FindInnermostStackful(a))
+	require.Nil(t, FindBizError(a))
+}